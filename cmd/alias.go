@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage aliases for contexts and namespaces",
+	Long: `Aliases are alternative names for contexts and namespaces that are stored in the kubectl-switch
+config file. They are accepted wherever a context or namespace name is, and are shown next to the
+real name in the pickers. The kubeconfig files themselves are never modified.`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:               "set <alias> <target>",
+	Short:             "Create or update an alias",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: getAliasSetCompletions,
+	Run: func(cmd *cobra.Command, args []string) {
+		alias, target := args[0], args[1]
+		if err := config.ValidateAliasName(alias); err != nil {
+			log.Fatalf("Invalid alias: %v", err)
+		}
+
+		kind, label := aliasKind(cmd)
+		if kind == config.AliasContext {
+			if err := configManager.LoadContexts(); err != nil {
+				log.Fatalf("Failed to load contexts: %v", err)
+			}
+			if slices.Contains(configManager.GetAllContexts(), alias) {
+				log.Fatalf("Alias '%s' conflicts with an existing context", alias)
+			}
+			if err := configManager.ValidateContext(target); err != nil {
+				log.Fatalf("Invalid alias target: %v", err)
+			}
+		}
+
+		if err := appConfig.SetAlias(kind, alias, target); err != nil {
+			log.Fatalf("Failed to set alias: %v", err)
+		}
+		log.Infof("Set %s alias '%s' for '%s'", label, alias, target)
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:               "rm <alias>",
+	Aliases:           []string{"remove"},
+	Short:             "Remove an alias",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: getAliasRmCompletions,
+	Run: func(cmd *cobra.Command, args []string) {
+		kind, label := aliasKind(cmd)
+		if err := appConfig.RemoveAlias(kind, args[0]); err != nil {
+			log.Fatalf("Failed to remove alias: %v", err)
+		}
+		log.Infof("Removed %s alias '%s'", label, args[0])
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the configured aliases",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(out, "KIND\tALIAS\tTARGET")
+		for _, alias := range appConfig.Aliases.Contexts {
+			_, _ = fmt.Fprintf(out, "context\t%s\t%s\n", alias.Name, alias.Target)
+		}
+		for _, alias := range appConfig.Aliases.Namespaces {
+			_, _ = fmt.Fprintf(out, "namespace\t%s\t%s\n", alias.Name, alias.Target)
+		}
+		if err := out.Flush(); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(aliasCmd)
+	aliasCmd.AddCommand(aliasSetCmd, aliasRmCmd, aliasListCmd)
+
+	aliasSetCmd.Flags().Bool("namespace", false, "Alias a namespace instead of a context")
+	aliasRmCmd.Flags().Bool("namespace", false, "Remove a namespace alias instead of a context alias")
+}
+
+// aliasKind returns the kind of alias the command works on, and how to refer to it in messages.
+func aliasKind(cmd *cobra.Command) (kind, label string) {
+	if namespace, _ := cmd.Flags().GetBool("namespace"); namespace {
+		return config.AliasNamespace, "namespace"
+	}
+	return config.AliasContext, "context"
+}
+
+// contextAliases shows context aliases in the picker and lets its filter match them.
+func contextAliases() ui.SelectOption {
+	return ui.WithAliases(appConfig.ContextAliases)
+}
+
+// namespaceAliases shows namespace aliases in the picker and lets its filter match them.
+func namespaceAliases() ui.SelectOption {
+	return ui.WithAliases(appConfig.NamespaceAliases)
+}
+
+// expandContextAlias returns the context an argument refers to if it is an alias rather than the
+// name of an existing context.
+func expandContextAlias(name string, contextNames []string) string {
+	if slices.Contains(contextNames, name) {
+		return name
+	}
+	if target, found := appConfig.ResolveContextAlias(name); found {
+		return target
+	}
+	return name
+}
+
+// withAliases returns the given names followed by the aliases of those of them that have any.
+func withAliases(names []string, aliasesOf func(string) []string) []string {
+	completions := slices.Clone(names)
+	for _, name := range names {
+		completions = append(completions, aliasesOf(name)...)
+	}
+	return completions
+}
+
+func getAliasSetCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if kind, _ := aliasKind(cmd); kind == config.AliasNamespace {
+		if err := configManager.LoadNamespaces(cmd.Context(), "", defaultNamespaceSelector(configManager.GetCurrentContext())); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return configManager.GetAllNamespaces(), cobra.ShellCompDirectiveNoFileComp
+	}
+	if err := configManager.LoadContexts(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return configManager.GetAllContexts(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func getAliasRmCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	aliases := appConfig.Aliases.Contexts
+	if kind, _ := aliasKind(cmd); kind == config.AliasNamespace {
+		aliases = appConfig.Aliases.Namespaces
+	}
+	var names []string
+	for _, alias := range aliases {
+		names = append(names, alias.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,13 +1,16 @@
 package cmd
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var contextCmd = &cobra.Command{
-	Use:               "context",
+	Use:               "context [context[:namespace]]",
 	Aliases:           []string{"ctx"},
 	Short:             "Switch the active Kubernetes context",
 	ValidArgsFunction: getContextCompletions,
@@ -17,38 +20,188 @@ var contextCmd = &cobra.Command{
 			log.Fatalf("Failed to load contexts: %v", err)
 		}
 
-		contextNames := configManager.GetAllContexts()
-		if len(contextNames) == 0 {
+		allContexts := configManager.GetAllContexts()
+		if len(allContexts) == 0 {
 			log.Fatal("No kubernetes contexts found in the provided directory")
 		}
 
-		var selectedContext string
+		// Only contexts matching the selector can be picked or switched to
+		selector, err := contextSelector(cmd)
+		if err != nil {
+			log.Fatalf("Invalid label selector: %v", err)
+		}
+		contextNames := allContexts
+		if groupName, _ := cmd.Flags().GetString("group"); groupName != "" {
+			contextNames, err = groupContexts(groupName, allContexts)
+			if err != nil {
+				log.Fatalf("Failed to load group: %v", err)
+			}
+		}
+		contextNames = selectContexts(contextNames, selector)
+		if len(contextNames) == 0 {
+			log.Fatal("No kubernetes contexts match the given group and selector")
+		}
+
+		query, _ := cmd.Flags().GetString("query")
+		if len(args) == 1 && query != "" {
+			log.Fatal("A context name and --query cannot be used together")
+		}
+
+		// A namespace can be given along with the context, or picked right after it
+		withNamespace, _ := cmd.Flags().GetBool("with-namespace")
+		var selectedContext, namespaceArg string
+
+		// Cycle through the contexts in their stable order, starting from the current one
+		next, _ := cmd.Flags().GetBool("next")
+		prev, _ := cmd.Flags().GetBool("prev")
+		if next || prev {
+			if len(args) == 1 || query != "" {
+				log.Fatal("--next and --prev cannot be used with a context name or --query")
+			}
+			step := 1
+			if prev {
+				step = -1
+			}
+			selectedContext = cycleContext(contextNames, configManager.GetCurrentContext(), step)
+		}
+
 		if len(args) == 1 {
-			selectedContext = args[0]
-		} else {
+			var hasNamespace bool
+			selectedContext, namespaceArg, hasNamespace = splitContextArg(args[0], allContexts)
+			withNamespace = withNamespace || hasNamespace
+			selectedContext = expandContextAlias(selectedContext, allContexts)
+
+			// Resolve partial names the same way the picker filters them
+			if !slices.Contains(contextNames, selectedContext) {
+				matches := ui.Match(contextNames, selectedContext, contextAliases(), contextLabels())
+				switch len(matches) {
+				case 0:
+					if !selector.Empty() {
+						log.Fatalf("No context matching '%s' found for the selector '%s'", selectedContext, selector)
+					}
+					// Leave it to SwitchToContext to report the name as not found
+				case 1:
+					selectedContext = matches[0]
+				default:
+					query = selectedContext
+				}
+			}
+		}
+
+		if selectedContext == "" || query != "" {
 			currentContext := configManager.GetCurrentContext()
-			selected, err := ui.Select("Choose a context:", contextNames, currentContext, appConfig.PageSize)
+			opts := append(contextOrder(), contextAliases(), contextLabels(), contextTags(), ui.WithQuery(query))
+			selected, err := ui.Select("Choose a context:", contextNames, currentContext, appConfig.PageSize, opts...)
 			if err != nil {
 				log.Fatalf("Failed to get user input: %v", err)
 			}
 			selectedContext = selected
 		}
 
-		if err := configManager.SwitchToContext(selectedContext); err != nil {
+		// Confirm and run the pre-switch hooks before the namespaces of the context's cluster are listed
+		if err := prepareSwitch(cmd, selectedContext, namespaceArg); err != nil {
 			log.Fatalf("Failed to switch context: %v", err)
 		}
 
-		log.Infof("Switched to context '%s'", selectedContext)
+		var selectedNamespace string
+		if withNamespace {
+			selectedNamespace, err = selectContextNamespace(cmd, selectedContext, namespaceArg)
+			if err != nil {
+				log.Fatalf("Failed to select namespace: %v", err)
+			}
+		}
+
+		if err := completeSwitch(cmd, selectedContext, selectedNamespace); err != nil {
+			log.Fatalf("Failed to switch context: %v", err)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(contextCmd)
+
+	contextCmd.Flags().StringP("query", "q", "", "Open the context picker with this filter pre-filled")
+	contextCmd.Flags().Bool("with-namespace", false, "Pick a namespace of the chosen context before switching")
+	contextCmd.Flags().StringP("selector", "l", "", "Label selector to narrow the contexts down to (e.g. env=prod,region=eu)")
+	contextCmd.Flags().StringP("group", "g", "", "Only offer the contexts of this group")
+	contextCmd.Flags().Bool("next", false, "Switch to the context after the current one (in the group, if given)")
+	contextCmd.Flags().Bool("prev", false, "Switch to the context before the current one (in the group, if given)")
+	contextCmd.MarkFlagsMutuallyExclusive("next", "prev")
+	contextCmd.Flags().Duration("for", 0, "Switch back to the previous context after this long (e.g. 15m)")
+	contextCmd.Flags().Bool("verify", false, "Check that the cluster accepts the credentials of the context after switching")
+	addConfirmFlags(contextCmd)
+	addEvalFlag(contextCmd)
+
+	err := contextCmd.RegisterFlagCompletionFunc("group", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return appConfig.GroupNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatalf("Failed to register flag completion: %v", err)
+	}
+}
+
+// splitContextArg splits a context[:namespace] argument. Since context names may contain colons
+// themselves (e.g. EKS ARNs), an argument naming an existing context is never split, and
+// otherwise only the last colon separates the namespace.
+func splitContextArg(arg string, contextNames []string) (contextName, namespace string, hasNamespace bool) {
+	if slices.Contains(contextNames, arg) {
+		return arg, "", false
+	}
+	idx := strings.LastIndex(arg, ":")
+	if idx < 0 {
+		return arg, "", false
+	}
+	return arg[:idx], arg[idx+1:], true
+}
+
+// selectContextNamespace resolves the namespace to use with the given context, opening the
+// namespace picker for that context's cluster if no namespace, or an ambiguous one, was given.
+func selectContextNamespace(cmd *cobra.Command, contextName, namespaceArg string) (string, error) {
+	ctx := cmd.Context()
+	selector := defaultNamespaceSelector(contextName)
+
+	query := namespaceArg
+	if namespaceArg != "" {
+		resolved, err := resolveNamespace(ctx, contextName, namespaceArg, selector)
+		if err != nil {
+			return "", err
+		}
+		if resolved != "" {
+			return resolved, nil
+		}
+	}
+
+	currentNamespace := configManager.GetContextNamespace(contextName)
+	return selectNamespace(cmd, contextName, selector, currentNamespace, query)
 }
 
 func getContextCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if err := configManager.LoadContexts(); err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
-	return configManager.GetAllContexts(), cobra.ShellCompDirectiveNoFileComp
+	contextNames := configManager.GetAllContexts()
+	selector, err := contextSelector(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	// Complete the namespace part of context:namespace arguments from that context's cluster
+	contextArg, _, hasNamespace := splitContextArg(toComplete, contextNames)
+	if contextName := expandContextAlias(contextArg, contextNames); hasNamespace && slices.Contains(contextNames, contextName) {
+		if err := configManager.LoadNamespaces(cmd.Context(), contextName, defaultNamespaceSelector(contextName)); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var completions []string
+		for _, ns := range withAliases(configManager.GetAllNamespaces(), appConfig.NamespaceAliases) {
+			completions = append(completions, contextArg+":"+ns)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	if groupName, _ := cmd.Flags().GetString("group"); groupName != "" {
+		if contextNames, err = groupContexts(groupName, contextNames); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+	}
+	return withAliases(selectContexts(contextNames, selector), appConfig.ContextAliases), cobra.ShellCompDirectiveNoFileComp
 }
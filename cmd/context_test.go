@@ -0,0 +1,35 @@
+package cmd
+
+import "testing"
+
+func TestSplitContextArg(t *testing.T) {
+	contextNames := []string{
+		"payments-prod",
+		"arn:aws:eks:eu-west-1:123456789012:cluster/payments",
+	}
+
+	tests := []struct {
+		arg           string
+		wantContext   string
+		wantNamespace string
+		wantSplit     bool
+	}{
+		{"payments-prod", "payments-prod", "", false},
+		{"payments-prod:checkout", "payments-prod", "checkout", true},
+		{"payments-prod:", "payments-prod", "", true},
+		{"pay", "pay", "", false},
+		{"pay:check", "pay", "check", true},
+		{"arn:aws:eks:eu-west-1:123456789012:cluster/payments", "arn:aws:eks:eu-west-1:123456789012:cluster/payments", "", false},
+		{"arn:aws:eks:eu-west-1:123456789012:cluster/payments:checkout", "arn:aws:eks:eu-west-1:123456789012:cluster/payments", "checkout", true},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			contextName, namespace, split := splitContextArg(tt.arg, contextNames)
+			if contextName != tt.wantContext || namespace != tt.wantNamespace || split != tt.wantSplit {
+				t.Errorf("splitContextArg(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.arg, contextName, namespace, split, tt.wantContext, tt.wantNamespace, tt.wantSplit)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// credentialStatus describes when the credentials of a context expire, as "expired" or "expires
+// in 3d", and whether they expired or expire within the configured warning period. It returns an
+// empty status if the expiry of the credentials is unknown.
+func credentialStatus(contextName string) (status string, warn bool) {
+	expiry := contextInfo(contextName).CredentialExpiry
+	if expiry.IsZero() {
+		return "", false
+	}
+	remaining := time.Until(expiry)
+	if remaining <= 0 {
+		return "expired", true
+	}
+	return "expires in " + duration.HumanDuration(remaining), remaining <= appConfig.CredentialExpiryWarning
+}
+
+// credentialBadge returns a tag for the picker if the credentials of a context expired or expire
+// soon, and an empty tag otherwise.
+func credentialBadge(contextName string) ui.Tag {
+	status, warn := credentialStatus(contextName)
+	if !warn {
+		return ui.Tag{}
+	}
+	color := "3"
+	if status == "expired" {
+		color = "1"
+	}
+	return ui.Tag{Text: status, Color: color}
+}
+
+// warnCredentialExpiry warns if the credentials of a context expired or expire soon.
+func warnCredentialExpiry(contextName string) {
+	expiry := contextInfo(contextName).CredentialExpiry
+	if _, warn := credentialStatus(contextName); !warn {
+		return
+	}
+	if remaining := time.Until(expiry); remaining > 0 {
+		log.Warnf("The credentials of context '%s' expire in %s", contextName, duration.HumanDuration(remaining))
+	} else {
+		log.Warnf("The credentials of context '%s' expired %s ago", contextName, duration.HumanDuration(-remaining))
+	}
+}
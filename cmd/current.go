@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var currentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Print the current context and namespace",
+	Long: `Current prints the active context as context:namespace, followed by its environment tag if it has
+one, for use in shell prompts. The tag is colored when printing to a terminal. Like other commands,
+current first reverts an expired time-boxed switch, so a prompt using it switches back once the
+time is up.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		contextName := configManager.GetCurrentContext()
+		if contextName == "" {
+			log.Fatal("No current context set")
+		}
+
+		name := contextName
+		if label := contextLabel(contextName); label != "" {
+			name = label
+		}
+		parts := []string{name}
+		if namespace := configManager.GetCurrentNamespace(); namespace != "" {
+			parts[0] += ":" + namespace
+		}
+		if tag := contextTag(contextName).Render(); tag != "" {
+			parts = append(parts, tag)
+		}
+		if status := leaseStatus(contextName); status != "" {
+			parts = append(parts, status)
+		}
+
+		if _, err := fmt.Fprintln(ui.ColorWriter(cmd.OutOrStdout()), strings.Join(parts, " ")); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(currentCmd)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"cmp"
+	"path/filepath"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	"github.com/mirceanton/kubectl-switch/v2/internal/manager"
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// contextDisplay collects what the context display template can show about a context, loading
+// the contexts first if needed.
+func contextDisplay(contextName string) config.ContextDisplay {
+	info := contextInfo(contextName)
+	aliases := appConfig.ContextAliases(contextName)
+	display := config.ContextDisplay{
+		Name:      contextName,
+		Short:     appConfig.RewriteContextName(contextName),
+		Aliases:   aliases,
+		Cluster:   info.Cluster,
+		User:      info.User,
+		Namespace: info.Namespace,
+		File:      filepath.Base(info.File),
+		Path:      info.File,
+	}
+	if len(aliases) > 0 {
+		display.Alias = aliases[0]
+	}
+	return display
+}
+
+// contextLabel returns how a context is shown according to the configured template and rewrites,
+// or an empty string if it is shown by its name.
+func contextLabel(contextName string) string {
+	if !appConfig.HasContextDisplay() {
+		return ""
+	}
+	return appConfig.DisplayContext(contextDisplay(contextName))
+}
+
+// contextLabels shows contexts in the picker the way contextLabel renders them.
+func contextLabels() ui.SelectOption {
+	labels := make(map[string]string)
+	for _, contextName := range configManager.GetAllContexts() {
+		labels[contextName] = contextLabel(contextName)
+	}
+	return ui.WithLabels(func(contextName string) string {
+		return labels[contextName]
+	})
+}
+
+// contextInfo returns the definition of a context, loading the contexts first if needed.
+func contextInfo(contextName string) manager.ContextInfo {
+	if len(configManager.GetAllContexts()) == 0 {
+		if err := configManager.LoadContexts(); err != nil {
+			log.Debugf("Failed to load contexts: %v", err)
+		}
+	}
+	return configManager.GetContextInfo(contextName)
+}
+
+// contextSettings returns the settings from the config file that apply to the given context.
+func contextSettings(contextName string) config.ContextSettings {
+	info := contextInfo(contextName)
+	ref := config.ContextRef{Name: contextName, Server: info.Server}
+	if info.File != "" {
+		ref.File = filepath.Base(info.File)
+	}
+	return appConfig.ForContext(ref)
+}
+
+// contextTag returns the environment tag of a context. Environments set in the config file take
+// precedence over those set in the kubeconfig file.
+func contextTag(contextName string) ui.Tag {
+	info := contextInfo(contextName)
+	settings := contextSettings(contextName)
+	environment := cmp.Or(settings.Environment, info.Environment)
+	color := cmp.Or(settings.Color, info.Color)
+	return ui.Tag{Text: environment, Color: config.EnvironmentColor(environment, color)}
+}
+
+// contextTags shows the environment tag of each context in the picker, followed by a badge if its
+// credentials expired or expire soon.
+func contextTags() ui.SelectOption {
+	tags := make(map[string][]ui.Tag)
+	for _, contextName := range configManager.GetAllContexts() {
+		tags[contextName] = []ui.Tag{contextTag(contextName), credentialBadge(contextName)}
+	}
+	return ui.WithTags(func(contextName string) []ui.Tag {
+		return tags[contextName]
+	})
+}
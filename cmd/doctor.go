@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/manager"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// doctorColumns are the checks shown in the doctor table, in order.
+var doctorColumns = []string{manager.CheckFile, manager.CheckPlugin, manager.CheckTLS, manager.CheckAPI, manager.CheckAuth}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose problems with the available contexts",
+	Long: `Doctor checks every context concurrently and prints a table of the results, followed by the
+details of each warning and failure:
+
+  file    the kubeconfig file is not accessible by group or others (--fix makes it private)
+  plugin  the exec credential plugin (e.g. aws, gke-gcloud-auth-plugin, kubelogin) is on the PATH
+  tls     TLS is used and verified, and the CA certificates are valid
+  api     the API server can be reached, and how long a request takes
+  auth    the cluster accepts the credentials
+
+Use --output json for a machine-readable report. Doctor exits with an error if any check failed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "table" && output != "json" {
+			log.Fatalf("Invalid output format: %s", output)
+		}
+
+		if err := configManager.LoadContexts(); err != nil {
+			log.Fatalf("Failed to load contexts: %v", err)
+		}
+		selector, err := contextSelector(cmd)
+		if err != nil {
+			log.Fatalf("Invalid label selector: %v", err)
+		}
+		contextNames := selectContexts(configManager.GetAllContexts(), selector)
+		if len(contextNames) == 0 {
+			log.Fatal("No kubernetes contexts found to check")
+		}
+
+		fix, _ := cmd.Flags().GetBool("fix")
+		results := configManager.Diagnose(cmd.Context(), contextNames, fix)
+
+		if output == "json" {
+			err = writeDoctorReport(cmd, results)
+		} else {
+			err = writeDoctorTable(cmd, results)
+		}
+		if err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+
+		var failed int
+		for _, result := range results {
+			if result.Failed() {
+				failed++
+			}
+		}
+		if failed > 0 {
+			log.Fatalf("%d of %d contexts failed checks", failed, len(results))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringP("output", "o", "table", "Output format (table, json)")
+	doctorCmd.Flags().StringP("selector", "l", "", "Label selector to filter contexts on (e.g. env=prod,region=eu)")
+	doctorCmd.Flags().Bool("fix", false, "Make kubeconfig files accessible by group or others private")
+}
+
+// writeDoctorTable prints a row of check statuses per context, followed by the details of the
+// checks that did not pass.
+func writeDoctorTable(cmd *cobra.Command, results []manager.Diagnosis) error {
+	out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(out, "CONTEXT\tFILE\tPLUGIN\tTLS\tAPI\tLATENCY\tAUTH")
+	for _, result := range results {
+		row := result.Context
+		for _, name := range doctorColumns {
+			status := string(result.Check(name).Status)
+			if status == string(manager.CheckSkip) {
+				status = "-"
+			}
+			row += "\t" + status
+			if name == manager.CheckAPI {
+				latency := "-"
+				if result.Latency > 0 {
+					latency = result.Latency.Round(time.Millisecond).String()
+				}
+				row += "\t" + latency
+			}
+		}
+		_, _ = fmt.Fprintln(out, row)
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		var header bool
+		for _, check := range result.Checks {
+			if check.Status != manager.CheckWarn && check.Status != manager.CheckFail {
+				continue
+			}
+			if !header {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%s (%s):\n", result.Context, result.File)
+				header = true
+			}
+			if _, err := fmt.Fprintf(cmd.OutOrStdout(), "  %s %s: %s\n", check.Status, check.Name, check.Message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// doctorReport is the JSON form of the diagnosis of a context.
+type doctorReport struct {
+	Context   string        `json:"context"`
+	File      string        `json:"file"`
+	Server    string        `json:"server,omitempty"`
+	LatencyMS int64         `json:"latencyMs,omitempty"`
+	Checks    []doctorCheck `json:"checks"`
+}
+
+// doctorCheck is the JSON form of a diagnostic check.
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// writeDoctorReport prints the diagnosis of every context as JSON.
+func writeDoctorReport(cmd *cobra.Command, results []manager.Diagnosis) error {
+	reports := make([]doctorReport, 0, len(results))
+	for _, result := range results {
+		report := doctorReport{
+			Context:   result.Context,
+			File:      result.File,
+			Server:    result.Server,
+			LatencyMS: result.Latency.Milliseconds(),
+			Checks:    []doctorCheck{},
+		}
+		for _, check := range result.Checks {
+			report.Checks = append(report.Checks, doctorCheck{Name: check.Name, Status: string(check.Status), Message: check.Message})
+		}
+		reports = append(reports, report)
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(reports)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Shells that --eval can print statements for
+const (
+	shellPOSIX = "posix"
+	shellFish  = "fish"
+)
+
+// addEvalFlag adds the flag for printing the environment variables of the new context to a command.
+func addEvalFlag(cmd *cobra.Command) {
+	cmd.Flags().String("eval", "", "Print statements setting the environment variables of the context for the given shell (posix, fish) to stdout; bash and zsh count as posix")
+	cmd.Flags().Lookup("eval").NoOptDefVal = shellPOSIX
+}
+
+// evalShell returns the shell given with --eval, or an empty string if it was not given.
+func evalShell(cmd *cobra.Command) (string, error) {
+	shell, _ := cmd.Flags().GetString("eval")
+	switch shell {
+	case "", shellPOSIX, shellFish:
+		return shell, nil
+	case "sh", "bash", "zsh":
+		return shellPOSIX, nil
+	default:
+		return "", fmt.Errorf("unsupported shell '%s' (use %s or %s)", shell, shellPOSIX, shellFish)
+	}
+}
+
+// contextEnv returns the environment variables of a context: those from its kubeconfig
+// extension, overridden by those from the config file.
+func contextEnv(contextName string) map[string]string {
+	env := make(map[string]string)
+	maps.Copy(env, contextInfo(contextName).Env)
+	maps.Copy(env, contextSettings(contextName).Env)
+	return env
+}
+
+// writeEnv prints statements for the given shell that set the environment variables of a context
+// (or the current one) and unset those of the previous context that it does not set itself.
+func writeEnv(cmd *cobra.Command, shell, previousContext, contextName string) error {
+	if shell == "" {
+		return nil
+	}
+	if contextName == "" {
+		contextName = configManager.GetCurrentContext()
+	}
+
+	env := contextEnv(contextName)
+	var b strings.Builder
+	if previousContext != "" && previousContext != contextName {
+		for _, name := range slices.Sorted(maps.Keys(contextEnv(previousContext))) {
+			if _, exists := env[name]; !exists {
+				statement, err := unsetStatement(shell, name)
+				if err != nil {
+					return err
+				}
+				b.WriteString(statement)
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		statement, err := exportStatement(shell, name, env[name])
+		if err != nil {
+			return err
+		}
+		b.WriteString(statement)
+	}
+
+	_, err := fmt.Fprint(cmd.OutOrStdout(), b.String())
+	return err
+}
+
+// exportStatement returns the statement setting an environment variable in the given shell. Names
+// are never quoted, so invalid ones are refused rather than passed to the shell.
+func exportStatement(shell, name, value string) (string, error) {
+	if !config.IsEnvName(name) {
+		return "", fmt.Errorf("invalid environment variable name '%s'", name)
+	}
+	if shell == shellFish {
+		value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+		return fmt.Sprintf("set -gx %s '%s';\n", name, value), nil
+	}
+	return fmt.Sprintf("export %s='%s';\n", name, strings.ReplaceAll(value, "'", `'\''`)), nil
+}
+
+// unsetStatement returns the statement removing an environment variable in the given shell,
+// refusing invalid names like exportStatement.
+func unsetStatement(shell, name string) (string, error) {
+	if !config.IsEnvName(name) {
+		return "", fmt.Errorf("invalid environment variable name '%s'", name)
+	}
+	if shell == shellFish {
+		return fmt.Sprintf("set -e %s;\n", name), nil
+	}
+	return fmt.Sprintf("unset %s;\n", name), nil
+}
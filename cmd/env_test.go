@@ -0,0 +1,61 @@
+package cmd
+
+import "testing"
+
+func TestExportStatement(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   string
+		varName string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"posix", shellPOSIX, "AWS_PROFILE", "prod", "export AWS_PROFILE='prod';\n", false},
+		{"posix empty", shellPOSIX, "AWS_PROFILE", "", "export AWS_PROFILE='';\n", false},
+		{"posix single quote", shellPOSIX, "MSG", "it's", "export MSG='it'\\''s';\n", false},
+		{"posix expansions", shellPOSIX, "MSG", "$HOME `id` $(id) \\n", "export MSG='$HOME `id` $(id) \\n';\n", false},
+		{"fish", shellFish, "AWS_PROFILE", "prod", "set -gx AWS_PROFILE 'prod';\n", false},
+		{"fish single quote", shellFish, "MSG", "it's", "set -gx MSG 'it\\'s';\n", false},
+		{"fish backslash", shellFish, "MSG", `C:\dir\`, `set -gx MSG 'C:\\dir\\';` + "\n", false},
+		{"invalid name", shellPOSIX, "BAD;rm -rf ~", "x", "", true},
+		{"leading digit", shellFish, "1PASSWORD", "x", "", true},
+		{"empty name", shellPOSIX, "", "x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exportStatement(tt.shell, tt.varName, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("exportStatement() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("exportStatement() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsetStatement(t *testing.T) {
+	tests := []struct {
+		shell   string
+		varName string
+		want    string
+		wantErr bool
+	}{
+		{shellPOSIX, "AWS_PROFILE", "unset AWS_PROFILE;\n", false},
+		{shellFish, "AWS_PROFILE", "set -e AWS_PROFILE;\n", false},
+		{shellPOSIX, "A B", "", true},
+		{shellFish, "$(id)", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell+"/"+tt.varName, func(t *testing.T) {
+			got, err := unsetStatement(tt.shell, tt.varName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unsetStatement() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("unsetStatement() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
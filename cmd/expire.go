@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var expireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "Switch back from an expired time-boxed switch",
+	Long: `Expire reverts the active time-boxed switch if it has expired, and does nothing otherwise. Most
+other commands do so as well before running, but expire does nothing else, which makes it cheap
+enough to run before every shell prompt; see init. With --eval, it also prints the statements that
+switch the environment variables back, as the ctx and ns commands do.`,
+	Args: cobra.NoArgs,
+	// Keep the output of a misconfiguration short, as it is repeated at every prompt
+	SilenceUsage: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		expireLease(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(expireCmd)
+
+	addEvalFlag(expireCmd)
+}
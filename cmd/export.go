@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [context...]",
+	Short: "Print a kubeconfig holding the given contexts",
+	Long: `Export prints a single kubeconfig holding the given contexts (or aliases), or the contexts
+matching --selector, along with their clusters and users, for sharing them or using them with
+tools that only read one kubeconfig file. Without either, the current context is exported.
+
+Relative file paths are resolved, and with --flatten the referenced certificate, key and token
+files are embedded, so the output works from anywhere. Mind that it contains credentials.`,
+	ValidArgsFunction: getExportCompletions,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := configManager.LoadContexts(); err != nil {
+			log.Fatalf("Failed to load contexts: %v", err)
+		}
+		allContexts := configManager.GetAllContexts()
+
+		selector, err := contextSelector(cmd)
+		if err != nil {
+			log.Fatalf("Invalid label selector: %v", err)
+		}
+
+		var contextNames []string
+		for _, arg := range args {
+			contextName := expandContextAlias(arg, allContexts)
+			if err := configManager.ValidateContext(contextName); err != nil {
+				log.Fatalf("Failed to export context: %v", err)
+			}
+			if !slices.Contains(contextNames, contextName) {
+				contextNames = append(contextNames, contextName)
+			}
+		}
+		if cmd.Flags().Changed("selector") {
+			for _, contextName := range selectContexts(allContexts, selector) {
+				if !slices.Contains(contextNames, contextName) {
+					contextNames = append(contextNames, contextName)
+				}
+			}
+		} else if len(args) == 0 {
+			currentContext := configManager.GetCurrentContext()
+			if currentContext == "" {
+				log.Fatal("No current context set")
+			}
+			contextNames = []string{currentContext}
+		}
+		if len(contextNames) == 0 {
+			log.Fatal("No kubernetes contexts match the given selector")
+		}
+
+		flatten, _ := cmd.Flags().GetBool("flatten")
+		kubeconfig, err := configManager.Export(contextNames, flatten)
+		if err != nil {
+			log.Fatalf("Failed to export contexts: %v", err)
+		}
+		data, err := clientcmd.Write(*kubeconfig)
+		if err != nil {
+			log.Fatalf("Failed to encode kubeconfig: %v", err)
+		}
+		if _, err := cmd.OutOrStdout().Write(data); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringP("selector", "l", "", "Label selector to export the matching contexts of (e.g. env=prod,region=eu)")
+	exportCmd.Flags().Bool("flatten", false, "Embed referenced certificate, key and token files")
+}
+
+func getExportCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := configManager.LoadContexts(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	selector, err := contextSelector(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	contextNames := selectContexts(configManager.GetAllContexts(), selector)
+	return withAliases(contextNames, appConfig.ContextAliases), cobra.ShellCompDirectiveNoFileComp
+}
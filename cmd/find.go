@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var findCmd = &cobra.Command{
+	Use:   "find <pattern>",
+	Short: "Find the contexts whose cluster has namespaces matching a pattern",
+	Long: `Find lists the namespaces of every context's cluster (or of the contexts matching --selector)
+concurrently and prints the ones matching the pattern, which is matched the same way the picker
+filters its list. When run in a terminal, it then offers to switch to one of the matches.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pattern := args[0]
+
+		if err := configManager.LoadContexts(); err != nil {
+			log.Fatalf("Failed to load contexts: %v", err)
+		}
+
+		selector, err := contextSelector(cmd)
+		if err != nil {
+			log.Fatalf("Invalid label selector: %v", err)
+		}
+		contextNames := selectContexts(configManager.GetAllContexts(), selector)
+		if len(contextNames) == 0 {
+			log.Fatal("No kubernetes contexts found to search")
+		}
+
+		namespaceSelector, _ := cmd.Flags().GetString("namespace-selector")
+		if _, err := labels.Parse(namespaceSelector); err != nil {
+			log.Fatalf("Invalid namespace label selector: %v", err)
+		}
+
+		results := configManager.LoadNamespacesAcross(cmd.Context(), contextNames, namespaceSelector)
+
+		// Print a table of matches and errors per context
+		var matches []string
+		// Keep stdout free for the shell statements when --eval is given
+		w := cmd.OutOrStdout()
+		if shell, _ := evalShell(cmd); shell != "" {
+			w = cmd.ErrOrStderr()
+		}
+		out := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(out, "CONTEXT\tNAMESPACES")
+		for _, result := range results {
+			if result.Err != nil {
+				_, _ = fmt.Fprintf(out, "%s\terror: %v\n", result.Context, result.Err)
+				continue
+			}
+
+			found := ui.Match(result.Namespaces, pattern)
+			if len(found) == 0 {
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "%s\t%s\n", result.Context, strings.Join(found, ", "))
+			for _, ns := range found {
+				matches = append(matches, result.Context+":"+ns)
+			}
+		}
+		if err := out.Flush(); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+
+		if len(matches) == 0 {
+			log.Fatalf("No namespaces matching '%s' found", pattern)
+		}
+
+		noSwitch, _ := cmd.Flags().GetBool("no-switch")
+		if noSwitch || !ui.CanPrompt() {
+			return
+		}
+
+		selected, err := ui.Select("Switch to:", matches, "", appConfig.PageSize)
+		if err != nil {
+			log.Fatalf("Failed to get user input: %v", err)
+		}
+
+		// The context part may itself contain colons, but namespaces never do
+		idx := strings.LastIndex(selected, ":")
+		contextName, namespace := selected[:idx], selected[idx+1:]
+		if err := switchContext(cmd, contextName, namespace); err != nil {
+			log.Fatalf("Failed to switch context: %v", err)
+		}
+	},
+}
+
+func init() {
+	namespaceCmd.AddCommand(findCmd)
+
+	findCmd.Flags().StringP("selector", "l", "", "Label selector to filter contexts on (e.g. env=production)")
+	findCmd.Flags().String("namespace-selector", "", "Label selector to filter namespaces on (e.g. team=payments)")
+	findCmd.Flags().Bool("no-switch", false, "Only print the matches, without offering to switch to one")
+	addConfirmFlags(findCmd)
+	addEvalFlag(findCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// groupContexts returns the contexts of a group in the order to cycle through them: the listed
+// contexts first, followed by those matching the group's selector in the order they were loaded.
+func groupContexts(name string, allContexts []string) ([]string, error) {
+	group, err := appConfig.Group(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	for _, contextName := range group.Contexts {
+		contextName = expandContextAlias(contextName, allContexts)
+		if !slices.Contains(allContexts, contextName) {
+			log.Warnf("Context '%s' of group '%s' not found", contextName, name)
+			continue
+		}
+		if !slices.Contains(members, contextName) {
+			members = append(members, contextName)
+		}
+	}
+
+	if group.Selector != "" {
+		selector, err := labels.Parse(group.Selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, contextName := range selectContexts(allContexts, selector) {
+			if !slices.Contains(members, contextName) {
+				members = append(members, contextName)
+			}
+		}
+	}
+	return members, nil
+}
+
+// cycleContext returns the context step places after the current one in contextNames, wrapping
+// around at either end. If the current context is not in the list, moving forward starts at the
+// first context and moving backward at the last one.
+func cycleContext(contextNames []string, current string, step int) string {
+	idx := slices.Index(contextNames, current)
+	if idx < 0 {
+		if step > 0 {
+			return contextNames[0]
+		}
+		return contextNames[len(contextNames)-1]
+	}
+	n := len(contextNames)
+	return contextNames[((idx+step)%n+n)%n]
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import "testing"
+
+func TestCycleContext(t *testing.T) {
+	contextNames := []string{"eu-prod", "us-prod", "ap-prod"}
+
+	tests := []struct {
+		name     string
+		contexts []string
+		current  string
+		step     int
+		want     string
+	}{
+		{"next", contextNames, "eu-prod", 1, "us-prod"},
+		{"previous", contextNames, "us-prod", -1, "eu-prod"},
+		{"next wraps around", contextNames, "ap-prod", 1, "eu-prod"},
+		{"previous wraps around", contextNames, "eu-prod", -1, "ap-prod"},
+		{"next from outside", contextNames, "dev", 1, "eu-prod"},
+		{"previous from outside", contextNames, "dev", -1, "ap-prod"},
+		{"next without current", contextNames, "", 1, "eu-prod"},
+		{"single context", []string{"eu-prod"}, "eu-prod", 1, "eu-prod"},
+		{"single context backward", []string{"eu-prod"}, "eu-prod", -1, "eu-prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cycleContext(tt.contexts, tt.current, tt.step); got != tt.want {
+				t.Errorf("cycleContext(%q, %d) = %q, want %q", tt.current, tt.step, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Phases of a switch that hooks run in
+const (
+	hookPre  = "pre"
+	hookPost = "post"
+)
+
+// runHooks runs the hooks configured for the given phase of a switch to a context (or the current
+// one) and namespace, stopping at the first one that fails. Hooks inherit the terminal, so they
+// can prompt for logins, but write their output to stderr to keep stdout clean.
+func runHooks(ctx context.Context, phase, contextName, namespace string) error {
+	if contextName == "" {
+		contextName = configManager.GetCurrentContext()
+	}
+	settings := contextSettings(contextName)
+	hooks := settings.PreSwitchHooks
+	if phase == hookPost {
+		hooks = settings.PostSwitchHooks
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	info := contextInfo(contextName)
+	if namespace == "" {
+		namespace = info.Namespace
+	}
+	env := append(os.Environ(),
+		"KUBECTL_SWITCH_HOOK="+phase,
+		"KUBECTL_SWITCH_CONTEXT="+contextName,
+		"KUBECTL_SWITCH_CLUSTER="+info.Cluster,
+		"KUBECTL_SWITCH_SERVER="+info.Server,
+		"KUBECTL_SWITCH_NAMESPACE="+namespace,
+		"KUBECTL_SWITCH_FILE="+info.File,
+	)
+
+	for _, hook := range hooks {
+		if err := runHook(ctx, hook, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runHook runs a single hook command with the given environment.
+func runHook(ctx context.Context, hook config.Hook, env []string) error {
+	log.Debugf("Running hook: %s", strings.Join(hook, " "))
+
+	c := exec.CommandContext(ctx, hook[0], hook[1:]...)
+	c.Env = env
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("hook '%s' failed: %w", strings.Join(hook, " "), err)
+	}
+	return nil
+}
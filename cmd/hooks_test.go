@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// hookConfig configures hooks that append a line naming themselves, the phase, context and
+// namespace to the file in $HOOK_LOG. The pre-switch hook of staging fails.
+const hookConfig = `pre-switch-hooks:
+  - [sh, -c, 'echo "global $KUBECTL_SWITCH_HOOK $KUBECTL_SWITCH_CONTEXT $KUBECTL_SWITCH_NAMESPACE" >> "$HOOK_LOG"']
+post-switch-hooks:
+  - [sh, -c, 'echo "global $KUBECTL_SWITCH_HOOK $KUBECTL_SWITCH_CONTEXT $KUBECTL_SWITCH_NAMESPACE" >> "$HOOK_LOG"']
+contexts:
+  - name: "*"
+    pre-switch-hooks:
+      - [sh, -c, 'echo "all $KUBECTL_SWITCH_HOOK $KUBECTL_SWITCH_CONTEXT $KUBECTL_SWITCH_NAMESPACE" >> "$HOOK_LOG"']
+  - name: prod
+    pre-switch-hooks:
+      - [sh, -c, 'echo "prod $KUBECTL_SWITCH_HOOK $KUBECTL_SWITCH_CONTEXT $KUBECTL_SWITCH_NAMESPACE" >> "$HOOK_LOG"']
+  - name: staging
+    pre-switch-hooks:
+      - ["false"]
+      - [sh, -c, 'echo "after failure" >> "$HOOK_LOG"']
+`
+
+// loadTestConfig loads the given config file content as the config used by the commands.
+func loadTestConfig(t *testing.T, content string) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	config.Init()
+	viper.Set("config", file)
+	viper.Set("kubeconfig-dir", t.TempDir())
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousConfig := appConfig
+	appConfig = cfg
+	t.Cleanup(func() {
+		appConfig = previousConfig
+	})
+}
+
+// setupHooks sets up the contexts of setupLease with dev active, loads hookConfig, and returns
+// the path of the hook log.
+func setupHooks(t *testing.T) string {
+	t.Helper()
+	setupLease(t, "dev", nil)
+	loadTestConfig(t, hookConfig)
+	hookLog := filepath.Join(t.TempDir(), "hooks.log")
+	t.Setenv("HOOK_LOG", hookLog)
+	return hookLog
+}
+
+// hookRuns returns the lines the hooks appended to the hook log.
+func hookRuns(t *testing.T, hookLog string) []string {
+	t.Helper()
+	data, err := os.ReadFile(hookLog)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestRunHooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		phase       string
+		contextName string
+		namespace   string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "global hooks first",
+			phase:       hookPre,
+			contextName: "prod",
+			namespace:   "payments",
+			want:        []string{"global pre prod payments", "all pre prod payments", "prod pre prod payments"},
+		},
+		{
+			name:  "current context",
+			phase: hookPre,
+			want:  []string{"global pre dev ", "all pre dev "},
+		},
+		{
+			name:        "post-switch hooks",
+			phase:       hookPost,
+			contextName: "prod",
+			want:        []string{"global post prod "},
+		},
+		{
+			name:        "stops at the first failure",
+			phase:       hookPre,
+			contextName: "staging",
+			want:        []string{"global pre staging ", "all pre staging "},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hookLog := setupHooks(t)
+
+			err := runHooks(context.Background(), tt.phase, tt.contextName, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runHooks() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := hookRuns(t, hookLog); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("hooks ran:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestSwitchContextHooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		contextName string
+		wantCurrent string
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "hooks around the switch",
+			contextName: "prod",
+			wantCurrent: "prod",
+			want:        []string{"global pre prod ", "all pre prod ", "prod pre prod ", "global post prod "},
+		},
+		{
+			name:        "failing pre-switch hook aborts",
+			contextName: "staging",
+			wantCurrent: "dev",
+			want:        []string{"global pre staging ", "all pre staging "},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hookLog := setupHooks(t)
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+
+			err := switchContext(cmd, tt.contextName, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("switchContext() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := configManager.GetCurrentContext(); got != tt.wantCurrent {
+				t.Errorf("current context = %q, want %q", got, tt.wantCurrent)
+			}
+			if got := hookRuns(t, hookLog); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("hooks ran:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// promptHooks holds the shell code installing the prompt hook for each supported shell. The hooks
+// keep the exit status of the previous command, which prompts commonly show.
+var promptHooks = map[string]string{
+	"bash": `_kubectl_switch_prompt() {
+  local status=$?
+  eval "$(command kubectl-switch expire --eval)"
+  return $status
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_kubectl_switch_prompt;"* ]]; then
+  PROMPT_COMMAND="_kubectl_switch_prompt${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`,
+	"zsh": `_kubectl_switch_prompt() {
+  local status=$?
+  eval "$(command kubectl-switch expire --eval)"
+  return $status
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _kubectl_switch_prompt
+`,
+	"fish": `function _kubectl_switch_prompt --on-event fish_prompt
+    command kubectl-switch expire --eval=fish | source
+end
+`,
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init <bash|zsh|fish>",
+	Short: "Print a shell hook that reverts expired time-boxed switches",
+	Long: `Init prints shell code that runs expire before every prompt, so that time-boxed switches are
+reverted as soon as they expire rather than on the next kubectl-switch command. Add it to your
+shell's startup file:
+
+  # ~/.bashrc or ~/.zshrc
+  eval "$(kubectl-switch init bash)"   # or zsh
+
+  # ~/.config/fish/config.fish
+  kubectl-switch init fish | source`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	// The hook is printed before the shell is fully set up, so it must not depend on the config
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	Run: func(cmd *cobra.Command, args []string) {
+		hook, supported := promptHooks[args[0]]
+		if !supported {
+			log.Fatalf("Unsupported shell '%s' (use bash, zsh or fish)", args[0])
+		}
+		if _, err := fmt.Fprint(cmd.OutOrStdout(), hook); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/state"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// updateLease makes a switch to the given context time-boxed if duration is positive, remembering
+// the context (and namespace) to revert to. Chained time-boxed switches keep reverting to where
+// the first one started, while regular switches end any time-boxed one.
+func updateLease(contextName, previousContext, previousNamespace string, duration time.Duration) {
+	s := loadState()
+	if duration <= 0 {
+		if s.Lease != nil {
+			log.Debugf("Ending time-boxed switch to context '%s'", s.Lease.Context)
+		}
+		s.Lease = nil
+		return
+	}
+
+	now := time.Now()
+	lease := &state.Lease{
+		Context:           contextName,
+		Expires:           now.Add(duration),
+		PreviousContext:   previousContext,
+		PreviousNamespace: previousNamespace,
+	}
+	if s.Lease != nil && now.Before(s.Lease.Expires) {
+		lease.PreviousContext, lease.PreviousNamespace = s.Lease.PreviousContext, s.Lease.PreviousNamespace
+	}
+	if lease.PreviousContext == contextName {
+		// There is nothing to go back to, so the active kubeconfig is cleared instead
+		lease.PreviousContext, lease.PreviousNamespace = "", ""
+	}
+	s.Lease = lease
+
+	expires := lease.Expires.Local().Format(time.Kitchen)
+	if lease.PreviousContext != "" {
+		log.Infof("Switching back to context '%s' at %s", lease.PreviousContext, expires)
+	} else {
+		log.Infof("Clearing the active kubeconfig at %s", expires)
+	}
+}
+
+// expireLease reverts an expired time-boxed switch if its context is still the active one,
+// replacing the active kubeconfig so that the context's credentials do not linger in it. With
+// --eval, the environment variables of the context are switched back as well.
+func expireLease(cmd *cobra.Command) {
+	s := loadState()
+	lease := s.Lease
+	if lease == nil || time.Now().Before(lease.Expires) {
+		return
+	}
+
+	if configManager.GetCurrentContext() == lease.Context {
+		if err := configManager.LoadContexts(); err != nil {
+			log.Warnf("Failed to load contexts: %v", err)
+		}
+
+		err := configManager.Revert(lease.Context, lease.PreviousContext, lease.PreviousNamespace)
+		if err != nil && lease.PreviousContext != "" {
+			log.Warnf("Failed to switch back to context '%s': %v", lease.PreviousContext, err)
+			lease.PreviousContext = ""
+			err = configManager.Revert(lease.Context, "", "")
+		}
+		if err != nil {
+			log.Warnf("Failed to end time-boxed switch to context '%s': %v", lease.Context, err)
+			return
+		}
+
+		if lease.PreviousContext != "" {
+			log.Infof("Time-boxed switch to context '%s' expired, switched back to context '%s'", lease.Context, lease.PreviousContext)
+		} else {
+			log.Infof("Time-boxed switch to context '%s' expired, cleared the active kubeconfig", lease.Context)
+		}
+		shell, err := evalShell(cmd)
+		if err == nil {
+			err = writeEnv(cmd, shell, lease.Context, lease.PreviousContext)
+		}
+		if err != nil {
+			log.Warnf("Failed to switch back environment variables: %v", err)
+		}
+		updateTerminalTitle()
+	}
+
+	s.Lease = nil
+	if err := s.Save(); err != nil {
+		log.Warnf("Failed to save state: %v", err)
+	}
+}
+
+// leaseStatus describes the time-boxed switch to the given context: how long it has left, or that
+// it expired but has not been reverted yet. It is empty if the context is not switched to for a
+// limited time.
+func leaseStatus(contextName string) string {
+	lease := loadState().Lease
+	if lease == nil || lease.Context != contextName {
+		return ""
+	}
+	if remaining := time.Until(lease.Expires); remaining > 0 {
+		return fmt.Sprintf("(%s left)", duration.HumanDuration(remaining))
+	}
+	return "(expired)"
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	"github.com/mirceanton/kubectl-switch/v2/internal/manager"
+	"github.com/mirceanton/kubectl-switch/v2/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// leaseKubeconfig returns a kubeconfig file defining the context, cluster and user of the given
+// name, with a token of name-token and AWS_PROFILE set to the name in eval mode.
+func leaseKubeconfig(name string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[1]s.example.com
+users:
+- name: %[1]s
+  user:
+    token: %[1]s-token
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+    extensions:
+    - name: kubectl-switch
+      extension:
+        env:
+          AWS_PROFILE: %[1]s
+current-context: %[1]s
+`, name)
+}
+
+// setupContexts points the manager and config used by the commands at a kubeconfig directory
+// with the given files, keyed by the name of their context, and an active kubeconfig switched to
+// the given context. It returns the path of the active kubeconfig.
+func setupContexts(t *testing.T, files map[string]string, active string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(files[active]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manager.NewManager(kubeconfig, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadContexts(); err != nil {
+		t.Fatal(err)
+	}
+
+	previousManager, previousConfig := configManager, appConfig
+	configManager, appConfig = m, &config.Config{}
+	t.Cleanup(func() {
+		configManager, appConfig = previousManager, previousConfig
+	})
+	return kubeconfig
+}
+
+// setupLease sets up the contexts prod, dev and staging with the given context active, and state
+// holding the given lease. It returns the path of the active kubeconfig.
+func setupLease(t *testing.T, active string, lease *state.Lease) string {
+	t.Helper()
+	files := make(map[string]string)
+	for _, name := range []string{"prod", "dev", "staging"} {
+		files[name] = leaseKubeconfig(name)
+	}
+	kubeconfig := setupContexts(t, files, active)
+
+	s, err := state.Load(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Lease = lease
+
+	previousState := appState
+	appState = s
+	t.Cleanup(func() {
+		appState = previousState
+	})
+	return kubeconfig
+}
+
+func TestUpdateLease(t *testing.T) {
+	now := time.Now()
+	running := &state.Lease{Context: "prod", Expires: now.Add(time.Hour), PreviousContext: "dev", PreviousNamespace: "payments"}
+	expired := &state.Lease{Context: "prod", Expires: now.Add(-time.Minute), PreviousContext: "dev"}
+
+	tests := []struct {
+		name              string
+		lease             *state.Lease
+		contextName       string
+		previousContext   string
+		previousNamespace string
+		duration          time.Duration
+		want              *state.Lease
+	}{
+		{name: "regular switch", contextName: "prod", previousContext: "dev"},
+		{name: "regular switch ends lease", lease: running, contextName: "staging", previousContext: "prod"},
+		{
+			name:              "time-boxed switch",
+			contextName:       "prod",
+			previousContext:   "dev",
+			previousNamespace: "payments",
+			duration:          15 * time.Minute,
+			want:              &state.Lease{Context: "prod", PreviousContext: "dev", PreviousNamespace: "payments"},
+		},
+		{
+			name:            "chained switch keeps where it started",
+			lease:           running,
+			contextName:     "staging",
+			previousContext: "prod",
+			duration:        15 * time.Minute,
+			want:            &state.Lease{Context: "staging", PreviousContext: "dev", PreviousNamespace: "payments"},
+		},
+		{
+			name:            "expired lease is not chained",
+			lease:           expired,
+			contextName:     "staging",
+			previousContext: "prod",
+			duration:        15 * time.Minute,
+			want:            &state.Lease{Context: "staging", PreviousContext: "prod"},
+		},
+		{
+			name:            "back to where it started",
+			lease:           running,
+			contextName:     "dev",
+			previousContext: "prod",
+			duration:        15 * time.Minute,
+			want:            &state.Lease{Context: "dev"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lease *state.Lease
+			if tt.lease != nil {
+				copied := *tt.lease
+				lease = &copied
+			}
+			setupLease(t, tt.previousContext, lease)
+
+			before := time.Now()
+			updateLease(tt.contextName, tt.previousContext, tt.previousNamespace, tt.duration)
+			after := time.Now()
+
+			got := appState.Lease
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("lease = %+v, want none", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("lease = none, want %+v", tt.want)
+			}
+			if got.Expires.Before(before.Add(tt.duration)) || got.Expires.After(after.Add(tt.duration)) {
+				t.Errorf("lease expires at %v, want %v from now", got.Expires, tt.duration)
+			}
+			got.Expires = time.Time{}
+			if *got != *tt.want {
+				t.Errorf("lease = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpireLease(t *testing.T) {
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		active      string
+		lease       state.Lease
+		wantCurrent string
+		wantNs      string
+		wantLease   bool
+		wantEval    string
+	}{
+		{
+			name:        "running",
+			active:      "prod",
+			lease:       state.Lease{Context: "prod", Expires: future, PreviousContext: "dev"},
+			wantCurrent: "prod",
+			wantLease:   true,
+		},
+		{
+			name:        "expired",
+			active:      "prod",
+			lease:       state.Lease{Context: "prod", Expires: past, PreviousContext: "dev", PreviousNamespace: "payments"},
+			wantCurrent: "dev",
+			wantNs:      "payments",
+			wantEval:    "export AWS_PROFILE='dev';\n",
+		},
+		{
+			name:     "expired without previous context",
+			active:   "prod",
+			lease:    state.Lease{Context: "prod", Expires: past},
+			wantEval: "unset AWS_PROFILE;\n",
+		},
+		{
+			name:     "expired with previous context gone",
+			active:   "prod",
+			lease:    state.Lease{Context: "prod", Expires: past, PreviousContext: "gone"},
+			wantEval: "unset AWS_PROFILE;\n",
+		},
+		{
+			name:        "expired after switching away",
+			active:      "staging",
+			lease:       state.Lease{Context: "prod", Expires: past, PreviousContext: "dev"},
+			wantCurrent: "staging",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := tt.lease
+			kubeconfig := setupLease(t, tt.active, &lease)
+			cmd := &cobra.Command{}
+			addEvalFlag(cmd)
+			if err := cmd.Flags().Set("eval", shellPOSIX); err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			expireLease(cmd)
+
+			if got := configManager.GetCurrentContext(); got != tt.wantCurrent {
+				t.Errorf("current context = %q, want %q", got, tt.wantCurrent)
+			}
+			if got := configManager.GetCurrentNamespace(); got != tt.wantNs {
+				t.Errorf("current namespace = %q, want %q", got, tt.wantNs)
+			}
+			if got := appState.Lease != nil; got != tt.wantLease {
+				t.Errorf("lease kept = %v, want %v", got, tt.wantLease)
+			}
+			if out.String() != tt.wantEval {
+				t.Errorf("eval output = %q, want %q", out.String(), tt.wantEval)
+			}
+
+			// The credentials of an expired context must not linger in the active or previous kubeconfig
+			active, err := os.ReadFile(kubeconfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lingers := strings.Contains(string(active), "prod-token"); lingers != (tt.wantCurrent == "prod") {
+				t.Errorf("credentials of prod in the active kubeconfig: %v", lingers)
+			}
+			if previous, err := os.ReadFile(kubeconfig + ".previous"); err == nil && strings.Contains(string(previous), "prod-token") {
+				t.Error("credentials of prod in the previous kubeconfig")
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/manager"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the kubeconfig files for problems",
+	Long: `Lint checks the kubeconfig files in the kubeconfig directory without contacting any cluster and
+reports, per file and context:
+
+  - files that cannot be parsed
+  - contexts referencing clusters or users that are not defined
+  - context, cluster and user names defined in several files
+  - certificate, key and token files that do not exist
+  - clusters with insecure-skip-tls-verify
+  - files with an empty or unknown current-context
+
+Lint exits with an error if it found any errors, or any warnings with --strict, so it can be used
+in CI. Use --output json for machine-readable output.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "table" && output != "json" {
+			log.Fatalf("Invalid output format: %s", output)
+		}
+
+		issues, err := configManager.Lint()
+		if err != nil {
+			log.Fatalf("Failed to lint kubeconfig files: %v", err)
+		}
+
+		if output == "json" {
+			err = writeLintReport(cmd, issues)
+		} else {
+			err = writeLintTable(cmd, issues)
+		}
+		if err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+
+		var errors, warnings int
+		for _, issue := range issues {
+			if issue.Severity == manager.LintError {
+				errors++
+			} else {
+				warnings++
+			}
+		}
+		strict, _ := cmd.Flags().GetBool("strict")
+		if errors > 0 || (strict && warnings > 0) {
+			log.Fatalf("Found %d errors and %d warnings", errors, warnings)
+		}
+		if output == "table" && len(issues) == 0 {
+			log.Info("No problems found")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringP("output", "o", "table", "Output format (table, json)")
+	lintCmd.Flags().Bool("strict", false, "Exit with an error on warnings as well")
+}
+
+// writeLintTable prints the issues as a table, with files relative to the kubeconfig directory.
+func writeLintTable(cmd *cobra.Command, issues []manager.LintIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+
+	out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(out, "SEVERITY\tFILE\tCONTEXT\tMESSAGE")
+	for _, issue := range issues {
+		file := issue.File
+		if rel, err := filepath.Rel(appConfig.KubeconfigDir, file); err == nil {
+			file = rel
+		}
+		_, _ = fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", issue.Severity, file, cmp.Or(issue.Context, "-"), issue.Message)
+	}
+	return out.Flush()
+}
+
+// lintReport is the JSON form of a lint issue.
+type lintReport struct {
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Context  string `json:"context,omitempty"`
+	Message  string `json:"message"`
+}
+
+// writeLintReport prints the issues as JSON.
+func writeLintReport(cmd *cobra.Command, issues []manager.LintIssue) error {
+	reports := make([]lintReport, 0, len(issues))
+	for _, issue := range issues {
+		reports = append(reports, lintReport{
+			Severity: string(issue.Severity),
+			File:     issue.File,
+			Context:  issue.Context,
+			Message:  issue.Message,
+		})
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(reports)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the available contexts",
+	Long: `List prints the contexts found in the kubeconfig directory, shown the same way as in the context
+picker. Use --output name to print only the real context names, one per line.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "table" && output != "name" {
+			log.Fatalf("Invalid output format: %s", output)
+		}
+
+		if err := configManager.LoadContexts(); err != nil {
+			log.Fatalf("Failed to load contexts: %v", err)
+		}
+
+		selector, err := contextSelector(cmd)
+		if err != nil {
+			log.Fatalf("Invalid label selector: %v", err)
+		}
+		contextNames := selectContexts(configManager.GetAllContexts(), selector)
+
+		if output == "name" {
+			for _, contextName := range contextNames {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), contextName)
+			}
+			return
+		}
+
+		showLabels, _ := cmd.Flags().GetBool("show-labels")
+		currentContext := configManager.GetCurrentContext()
+
+		// The colored environment tag comes last, so its escape codes do not throw off the alignment
+		out := tabwriter.NewWriter(ui.ColorWriter(cmd.OutOrStdout()), 0, 0, 2, ' ', 0)
+		header := "CURRENT\tNAME\tNAMESPACE\t"
+		if showLabels {
+			header += "LABELS\t"
+		}
+		_, _ = fmt.Fprintln(out, header+"CREDENTIALS\tENVIRONMENT")
+		for _, contextName := range contextNames {
+			var current string
+			if contextName == currentContext {
+				current = "*"
+			}
+			name := contextName
+			if label := contextLabel(contextName); label != "" {
+				name = label
+			}
+			row := fmt.Sprintf("%s\t%s\t%s\t", current, name, configManager.GetContextInfo(contextName).Namespace)
+			if showLabels {
+				row += cmp.Or(contextLabelSet(contextName).String(), "<none>") + "\t"
+			}
+			status, _ := credentialStatus(contextName)
+			row += status + "\t"
+			_, _ = fmt.Fprintln(out, row+contextTag(contextName).Render())
+		}
+		if err := out.Flush(); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringP("output", "o", "table", "Output format (table, name)")
+	listCmd.Flags().StringP("selector", "l", "", "Label selector to filter contexts on (e.g. env=prod,region=eu)")
+	listCmd.Flags().Bool("show-labels", false, "Show the labels of each context")
+}
@@ -1,9 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/manager"
 	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var namespaceCmd = &cobra.Command{
@@ -13,42 +27,359 @@ var namespaceCmd = &cobra.Command{
 	Args:              cobra.MaximumNArgs(1),
 	ValidArgsFunction: getNamespaceCompletions,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := configManager.LoadNamespaces(); err != nil {
-			log.Fatalf("Failed to load namespaces: %v", err)
+		contextName, err := targetContext(cmd)
+		if err != nil {
+			log.Fatalf("Failed to load context: %v", err)
+		}
+
+		selector, err := namespaceSelector(cmd, contextName)
+		if err != nil {
+			log.Fatalf("Invalid label selector: %v", err)
 		}
 
-		namespaceNames := configManager.GetAllNamespaces()
-		if len(namespaceNames) == 0 {
-			log.Fatal("No kubernetes namespaces found in the current cluster")
+		query, _ := cmd.Flags().GetString("query")
+		if len(args) == 1 && query != "" {
+			log.Fatal("A namespace name and --query cannot be used together")
+		}
+
+		create, _ := cmd.Flags().GetBool("create")
+		if create && len(args) == 0 {
+			log.Fatal("--create requires the name of the namespace to create")
 		}
 
 		var selectedNamespace string
 		if len(args) == 1 {
 			selectedNamespace = args[0]
-		} else {
+		}
+
+		// Run the pre-switch hooks, which may log in, before the cluster is accessed
+		if err := prepareNamespaceSwitch(cmd, contextName, selectedNamespace); err != nil {
+			log.Fatalf("Failed to switch namespace: %v", err)
+		}
+
+		if len(args) == 1 {
+			force, _ := cmd.Flags().GetBool("force")
+			if create {
+				if err := createNamespace(cmd, contextName, selectedNamespace); err != nil {
+					log.Fatalf("Failed to create namespace: %v", err)
+				}
+			} else if force {
+				if target, found := appConfig.ResolveNamespaceAlias(selectedNamespace); found {
+					selectedNamespace = target
+				}
+			} else {
+				resolved, err := resolveNamespace(cmd.Context(), contextName, selectedNamespace, selector)
+				if err != nil {
+					log.Fatalf("Failed to validate namespace (use --force to skip): %v", err)
+				}
+				if resolved == "" {
+					query = selectedNamespace
+				}
+				selectedNamespace = resolved
+			}
+		}
+
+		if len(args) == 0 || query != "" {
 			currentNamespace := configManager.GetCurrentNamespace()
-			selected, err := ui.Select("Choose a namespace:", namespaceNames, currentNamespace, appConfig.PageSize)
+			if contextName != "" {
+				currentNamespace = configManager.GetContextNamespace(contextName)
+			}
+			selected, err := selectNamespace(cmd, contextName, selector, currentNamespace, query)
 			if err != nil {
-				log.Fatalf("Failed to get user input: %v", err)
+				log.Fatalf("Failed to select namespace: %v", err)
 			}
 			selectedNamespace = selected
 		}
 
-		if err := configManager.SwitchToNamespace(selectedNamespace); err != nil {
-			log.Fatalf("Failed to switch namespace: %v", err)
+		if contextName != "" {
+			if err := configManager.SetContextNamespace(contextName, selectedNamespace); err != nil {
+				log.Fatalf("Failed to set namespace: %v", err)
+			}
+			log.Infof("Set namespace of context '%s' to '%s'", contextName, selectedNamespace)
+			if err := runHooks(cmd.Context(), hookPost, contextName, selectedNamespace); err != nil {
+				log.Warnf("Post-switch hook failed: %v", err)
+			}
+			return
 		}
 
-		log.Infof("Switched to namespace '%s'", selectedNamespace)
+		if err := completeNamespaceSwitch(cmd, selectedNamespace); err != nil {
+			log.Fatalf("Failed to switch namespace: %v", err)
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(namespaceCmd)
+
+	namespaceCmd.Flags().String("context", "", "Set the namespace of this context in its kubeconfig file instead of the active one")
+	namespaceCmd.Flags().Bool("force", false, "Switch to the given namespace without checking that it exists")
+	namespaceCmd.Flags().Bool("create", false, "Create the given namespace if it does not exist")
+	namespaceCmd.Flags().StringToString("labels", nil, "Labels to set on namespaces created with --create or from the picker")
+	namespaceCmd.Flags().StringToString("annotations", nil, "Annotations to set on namespaces created with --create or from the picker")
+	namespaceCmd.Flags().StringP("query", "q", "", "Open the namespace picker with this filter pre-filled")
+	addEvalFlag(namespaceCmd)
+	namespaceCmd.Flags().StringP("selector", "l", "", "Label selector to filter namespaces on (e.g. team=payments,env!=sandbox)")
+
+	err := namespaceCmd.RegisterFlagCompletionFunc("context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if err := configManager.LoadContexts(); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return withAliases(configManager.GetAllContexts(), appConfig.ContextAliases), cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatalf("Failed to register flag completion: %v", err)
+	}
+
+	namespaceCmd.Flags().StringSlice("label-columns", nil, "Label keys to show as columns in the namespace picker (env: NAMESPACE_LABEL_COLUMNS)")
+	err = viper.BindPFlag("namespace-label-columns", namespaceCmd.Flags().Lookup("label-columns"))
+	if err != nil {
+		log.Fatalf("Failed to bind flag: %v", err)
+	}
+
+	namespaceCmd.Flags().Bool("pods", false, "Show the number of pods in each namespace in the namespace picker (env: NAMESPACE_PODS)")
+	err = viper.BindPFlag("namespace-pods", namespaceCmd.Flags().Lookup("pods"))
+	if err != nil {
+		log.Fatalf("Failed to bind flag: %v", err)
+	}
+}
+
+// selectNamespace shows the namespace picker for the given context (or the current one) right
+// away and lets namespaces stream in as they are listed. When the filter matches no namespace,
+// the picker offers to create one with that name.
+func selectNamespace(cmd *cobra.Command, contextName, selector, current, query string) (string, error) {
+	load := func(ctx context.Context, sink *ui.Sink) error {
+		return watchNamespaces(ctx, contextName, selector, sink)
+	}
+	create := func(name string) error {
+		return createNamespace(cmd, contextName, name)
+	}
+	opts := append(namespaceOrder(contextName), namespaceAliases(), ui.WithQuery(query), ui.WithCreate(create))
+	return ui.SelectAsync(cmd.Context(), "Choose a namespace:", namespaceColumns(), load, current, appConfig.PageSize, opts...)
+}
+
+// createNamespace creates a namespace in the cluster of the given context (or the current one),
+// applying the labels and annotations given on the command line.
+func createNamespace(cmd *cobra.Command, contextName, name string) error {
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace name '%s': %s", name, strings.Join(errs, ", "))
+	}
+
+	// The context command has no label or annotation flags
+	var labels, annotations map[string]string
+	if cmd.Flags().Lookup("labels") != nil {
+		labels, _ = cmd.Flags().GetStringToString("labels")
+		annotations, _ = cmd.Flags().GetStringToString("annotations")
+	}
+
+	created, err := configManager.CreateNamespace(cmd.Context(), contextName, name, labels, annotations)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Infof("Created namespace '%s'", name)
+	}
+	return nil
+}
+
+// resolveNamespace resolves a namespace argument (or alias) for the given context (or the current
+// one) the same way the picker filters namespaces. It returns the namespace to switch to, or an empty
+// string if several namespaces match and the user has to pick one.
+func resolveNamespace(ctx context.Context, contextName, name, selector string) (string, error) {
+	if err := configManager.LoadNamespaces(ctx, contextName, selector); err != nil {
+		return "", err
+	}
+
+	namespaceNames := configManager.GetAllNamespaces()
+	if slices.Contains(namespaceNames, name) {
+		return name, nil
+	}
+	if target, found := appConfig.ResolveNamespaceAlias(name); found {
+		name = target
+		if slices.Contains(namespaceNames, name) {
+			return name, nil
+		}
+		if err := configManager.ValidateNamespace(ctx, contextName, name); err != nil {
+			return "", err
+		}
+		return name, nil
+	}
+
+	matches := ui.Match(namespaceNames, name, namespaceAliases())
+	switch len(matches) {
+	case 0:
+		// Namespaces outside the selector can still be used by their exact name
+		if err := configManager.ValidateNamespace(ctx, contextName, name); err != nil {
+			return "", err
+		}
+		return name, nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", nil
+	}
+}
+
+// targetContext returns the context given with --context, or an empty string for the current one.
+func targetContext(cmd *cobra.Command) (string, error) {
+	contextName, _ := cmd.Flags().GetString("context")
+	if contextName == "" {
+		return "", nil
+	}
+
+	if err := configManager.LoadContexts(); err != nil {
+		return "", err
+	}
+	contextName = expandContextAlias(contextName, configManager.GetAllContexts())
+	if !slices.Contains(configManager.GetAllContexts(), contextName) {
+		// Accept a partial name if it only matches a single context
+		matches := ui.Match(configManager.GetAllContexts(), contextName, contextAliases(), contextLabels())
+		if len(matches) != 1 {
+			return "", configManager.ValidateContext(contextName)
+		}
+		contextName = matches[0]
+	}
+	return contextName, nil
+}
+
+// namespaceSelector returns the label selector for listing namespaces of the given context (or
+// the current one): the --selector flag if it was given (even if empty), otherwise the default
+// configured for that context.
+func namespaceSelector(cmd *cobra.Command, contextName string) (string, error) {
+	if contextName == "" {
+		contextName = configManager.GetCurrentContext()
+	}
+	selector := defaultNamespaceSelector(contextName)
+	if cmd.Flags().Changed("selector") {
+		selector, _ = cmd.Flags().GetString("selector")
+	}
+
+	if _, err := labels.Parse(selector); err != nil {
+		return "", err
+	}
+	return selector, nil
+}
+
+// defaultNamespaceSelector returns the label selector configured for the given context.
+func defaultNamespaceSelector(contextName string) string {
+	return contextSettings(contextName).NamespaceSelector
+}
+
+// namespaceColumns returns the columns shown next to each namespace in the picker.
+func namespaceColumns() []ui.Column {
+	columns := []ui.Column{{Title: "Phase"}, {Title: "Age"}}
+	for _, label := range appConfig.NamespaceLabelColumns {
+		// Prefixed keys such as app.kubernetes.io/team are titled by their name only
+		columns = append(columns, ui.Column{Title: path.Base(label)})
+	}
+	if appConfig.NamespacePods {
+		columns = append(columns, ui.Column{Title: "Pods"})
+	}
+	return columns
+}
+
+// optionSink receives the options of a running selection prompt; ui.Sink is the one used outside
+// of tests.
+type optionSink interface {
+	Add(options ...ui.Option)
+	Remove(values ...string)
+	Synced()
+}
+
+// watchNamespaces feeds the namespaces of the given context's cluster (or the current one) into a
+// running selection prompt, filling in pod counts in the background when they are enabled.
+func watchNamespaces(ctx context.Context, contextName, selector string, sink optionSink) error {
+	var mu sync.Mutex
+	known := make(map[string]manager.Namespace)
+	pods := make(map[string]int)
+
+	// Pod counts stop with ctx, but are waited for so that none arrives after returning
+	var counting sync.WaitGroup
+	defer counting.Wait()
+
+	countPods := func(namespaces []manager.Namespace) {
+		names := make([]string, len(namespaces))
+		for i, ns := range namespaces {
+			names[i] = ns.Name
+		}
+		counting.Go(func() {
+			_ = configManager.CountPods(ctx, contextName, names, func(name string, count int) {
+				mu.Lock()
+				defer mu.Unlock()
+				ns, exists := known[name]
+				if !exists {
+					return
+				}
+				pods[name] = count
+				sink.Add(namespaceOption(ns, count, true))
+			})
+		})
+	}
+
+	return configManager.WatchNamespaces(ctx, contextName, selector, func(event manager.NamespaceEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(event.Added) > 0 {
+			var uncounted []manager.Namespace
+			options := make([]ui.Option, 0, len(event.Added))
+			for _, ns := range event.Added {
+				known[ns.Name] = ns
+				count, counted := pods[ns.Name]
+				if !counted {
+					uncounted = append(uncounted, ns)
+				}
+				options = append(options, namespaceOption(ns, count, counted))
+			}
+			sink.Add(options...)
+
+			if appConfig.NamespacePods && len(uncounted) > 0 {
+				countPods(uncounted)
+			}
+		}
+		if len(event.Removed) > 0 {
+			for _, name := range event.Removed {
+				delete(known, name)
+				delete(pods, name)
+			}
+			sink.Remove(event.Removed...)
+		}
+		if event.Synced {
+			sink.Synced()
+		}
+	})
+}
+
+// namespaceOption renders a namespace as a picker option with one cell per namespace column.
+func namespaceOption(ns manager.Namespace, pods int, counted bool) ui.Option {
+	age := time.Since(ns.Created)
+	cells := []ui.Cell{
+		{Text: ns.Phase},
+		{Text: duration.HumanDuration(age), Key: int64(age.Seconds())},
+	}
+	for _, label := range appConfig.NamespaceLabelColumns {
+		cells = append(cells, ui.Cell{Text: ns.Labels[label]})
+	}
+	if appConfig.NamespacePods {
+		if counted {
+			cells = append(cells, ui.Cell{Text: strconv.Itoa(pods), Key: int64(pods)})
+		} else {
+			cells = append(cells, ui.Cell{Text: "…", Key: -1})
+		}
+	}
+	return ui.Option{Value: ns.Name, Cells: cells}
 }
 
 func getNamespaceCompletions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if err := configManager.LoadNamespaces(); err != nil {
+	contextName, err := targetContext(cmd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	selector, err := namespaceSelector(cmd, contextName)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if err := configManager.LoadNamespaces(cmd.Context(), contextName, selector); err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
-	return configManager.GetAllNamespaces(), cobra.ShellCompDirectiveNoFileComp
+	return withAliases(configManager.GetAllNamespaces(), appConfig.NamespaceAliases), cobra.ShellCompDirectiveNoFileComp
 }
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// recordingSink keeps the options a selection prompt would show.
+type recordingSink struct {
+	mu      sync.Mutex
+	options map[string]string
+	synced  bool
+}
+
+func (s *recordingSink) Add(options ...ui.Option) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, opt := range options {
+		var cells []string
+		for _, cell := range opt.Cells {
+			cells = append(cells, cell.Text)
+		}
+		s.options[opt.Value] = strings.Join(cells, " ")
+	}
+}
+
+func (s *recordingSink) Remove(values ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, value := range values {
+		delete(s.options, value)
+	}
+}
+
+func (s *recordingSink) Synced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.synced = true
+}
+
+func (s *recordingSink) state() (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.options), s.synced
+}
+
+// testNamespace returns a namespace as served by the API server, created ten days ago.
+func testNamespace(name, resourceVersion string, phase corev1.NamespacePhase) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion, CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * 24 * time.Hour))},
+		Status:     corev1.NamespaceStatus{Phase: phase},
+	}
+}
+
+// namespaceServer serves a namespace listing, a single watch stream with the given events, and
+// pod listings with as many pods as the namespace name is long.
+func namespaceServer(t *testing.T, listed []*corev1.Namespace, events []watch.Event) *httptest.Server {
+	t.Helper()
+	var watched atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		if r.URL.Query().Get("watch") != "true" {
+			list := corev1.NamespaceList{
+				TypeMeta: metav1.TypeMeta{Kind: "NamespaceList", APIVersion: "v1"},
+				ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+			}
+			for _, ns := range listed {
+				list.Items = append(list.Items, *ns)
+			}
+			_ = encoder.Encode(list)
+			return
+		}
+
+		// Later watches, after the stream ended, see no changes
+		if !watched.Swap(true) {
+			for _, event := range events {
+				raw, err := json.Marshal(event.Object)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_ = encoder.Encode(metav1.WatchEvent{Type: string(event.Type), Object: runtime.RawExtension{Raw: raw}})
+			}
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/api/v1/namespaces/{namespace}/pods", func(w http.ResponseWriter, r *http.Request) {
+		list := corev1.PodList{TypeMeta: metav1.TypeMeta{Kind: "PodList", APIVersion: "v1"}}
+		for range len(r.PathValue("namespace")) {
+			list.Items = append(list.Items, corev1.Pod{})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// setupCluster sets up a context named test for the given API server.
+func setupCluster(t *testing.T, server string) {
+	t.Helper()
+	kubeconfig := strings.ReplaceAll(leaseKubeconfig("test"), "https://test.example.com", server)
+	setupContexts(t, map[string]string{"test": kubeconfig}, "test")
+}
+
+func TestWatchNamespaces(t *testing.T) {
+	listed := []*corev1.Namespace{
+		testNamespace("default", "1", corev1.NamespaceActive),
+		testNamespace("payments", "1", corev1.NamespaceActive),
+		testNamespace("orders", "1", corev1.NamespaceActive),
+	}
+	events := []watch.Event{
+		{Type: watch.Modified, Object: testNamespace("payments", "2", corev1.NamespaceTerminating)},
+		{Type: watch.Deleted, Object: testNamespace("orders", "3", corev1.NamespaceActive)},
+		{Type: watch.Added, Object: testNamespace("billing", "4", corev1.NamespaceActive)},
+		// Deletions of namespaces that were never reported are ignored
+		{Type: watch.Deleted, Object: testNamespace("unknown", "5", corev1.NamespaceActive)},
+	}
+
+	tests := []struct {
+		name string
+		pods bool
+		want map[string]string
+	}{
+		{
+			name: "namespaces",
+			want: map[string]string{
+				"default":  "Active 10d",
+				"payments": "Terminating 10d",
+				"billing":  "Active 10d",
+			},
+		},
+		{
+			name: "namespaces with pod counts",
+			pods: true,
+			want: map[string]string{
+				"default":  "Active 10d 7",
+				"payments": "Terminating 10d 8",
+				"billing":  "Active 10d 7",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := namespaceServer(t, listed, events)
+			setupCluster(t, server.URL)
+			appConfig.NamespacePods = tt.pods
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			sink := &recordingSink{options: make(map[string]string)}
+			done := make(chan error, 1)
+			go func() {
+				done <- watchNamespaces(ctx, "test", "", sink)
+			}()
+
+			var got map[string]string
+			var synced bool
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				if got, synced = sink.state(); synced && maps.Equal(got, tt.want) {
+					break
+				}
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("watchNamespaces() failed: %v", err)
+			}
+			if !synced {
+				t.Error("watchNamespaces() never reported the initial listing as complete")
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("options = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// addConfirmFlags adds the flags for confirming switches to protected contexts to a command.
+func addConfirmFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("yes", "y", false, "Switch to protected contexts without asking for confirmation")
+	cmd.Flags().String("reason", "", "Reason for switching to a protected context, recorded in the state file")
+}
+
+// contextProtected reports whether switching to the given context requires confirmation: config
+// file entries take precedence over the kubeconfig extension and the context's environment.
+func contextProtected(contextName string) bool {
+	if protected := contextSettings(contextName).Protected; protected != nil {
+		return *protected
+	}
+	info := contextInfo(contextName)
+	return info.Protected || appConfig.IsProtectedEnvironment(contextTag(contextName).Text)
+}
+
+// confirmProtected asks the user to confirm a switch to the given context by typing its name if
+// it is protected, unless it already is the current context or --yes was given. Confirmed
+// switches are recorded along with the reason, which is asked for if --reason was not given.
+func confirmProtected(cmd *cobra.Command, contextName string) error {
+	if !contextProtected(contextName) || contextName == configManager.GetCurrentContext() {
+		return nil
+	}
+
+	reason, _ := cmd.Flags().GetString("reason")
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		if !ui.CanPrompt() {
+			return fmt.Errorf("context '%s' is protected, pass --yes to switch to it", contextName)
+		}
+
+		_, err := ui.Input(fmt.Sprintf("Type '%s' to switch to this protected context:", contextName), contextName, func(value string) error {
+			if value != contextName {
+				return fmt.Errorf("does not match the context name")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if reason == "" {
+			reason, err = ui.Input("Reason (optional):", "Press enter to skip", nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if reason != "" {
+		log.Infof("Switching to protected context '%s': %s", contextName, reason)
+	}
+	loadState().RecordProtectedSwitch(contextName, reason, time.Now())
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+)
+
+// contextOrder returns the picker options that order contexts according to the configured sort
+// order, with favorite contexts pinned to the top.
+func contextOrder() []ui.SelectOption {
+	usage := loadState().Contexts
+	now := time.Now()
+	return pickerOrder(ui.Ranking{
+		Pinned: appConfig.IsFavoriteContext,
+		Score: func(contextName string) float64 {
+			return usage[contextName].Frecency(now)
+		},
+	})
+}
+
+// namespaceOrder returns the picker options that order the namespaces of the given context (or
+// the current one) according to the configured sort order, with favorite namespaces pinned.
+func namespaceOrder(contextName string) []ui.SelectOption {
+	if contextName == "" {
+		contextName = configManager.GetCurrentContext()
+	}
+	settings := contextSettings(contextName)
+	usage := loadState().Namespaces[contextName]
+	now := time.Now()
+	return pickerOrder(ui.Ranking{
+		Pinned: settings.IsFavoriteNamespace,
+		Score: func(namespace string) float64 {
+			return usage[namespace].Frecency(now)
+		},
+	})
+}
+
+// pickerOrder applies the configured sort order to a ranking: recent keeps it as is, name sorts
+// by name and file keeps the order the options were listed in, both only keeping pinned options.
+func pickerOrder(ranking ui.Ranking) []ui.SelectOption {
+	switch appConfig.Sort {
+	case config.SortName:
+		return []ui.SelectOption{ui.WithRanking(ui.Ranking{Pinned: ranking.Pinned}), ui.WithSortByValue()}
+	case config.SortFile:
+		return []ui.SelectOption{ui.WithRanking(ui.Ranking{Pinned: ranking.Pinned})}
+	default:
+		return []ui.SelectOption{ui.WithRanking(ranking)}
+	}
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/mirceanton/kubectl-switch/v2/internal/config"
 	"github.com/mirceanton/kubectl-switch/v2/internal/manager"
@@ -37,15 +40,24 @@ var rootCmd = &cobra.Command{
 		log.SetFormatter(appConfig.LogFormat)
 
 		// Create manager with config
-		configManager, err = manager.NewManager(appConfig.Kubeconfig, appConfig.KubeconfigDir)
+		configManager, err = manager.NewManager(appConfig.Kubeconfig, appConfig.KubeconfigDir, appConfig.RequestTimeout)
 		if err != nil {
 			return err
 		}
+
+		// Revert expired time-boxed switches before doing anything else, but not while completing,
+		// or in expire, which does so itself
+		switch {
+		case cmd.Name() == cobra.ShellCompRequestCmd, cmd.Name() == cobra.ShellCompNoDescRequestCmd:
+		case cmd == expireCmd:
+		default:
+			expireLease(cmd)
+		}
 		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 1 && args[0] == "-" {
-			if err := configManager.Restore(); err != nil {
+			if err := switchBack(cmd); err != nil {
 				log.Fatalf("Failed to switch to previous config: %v", err)
 			}
 			return nil
@@ -55,7 +67,12 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel in-flight cluster requests on Ctrl+C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
@@ -65,8 +82,17 @@ func init() {
 	cobra.OnInitialize(config.Init)
 
 	// Bind flags to Viper
+	addConfirmFlags(rootCmd)
+	addEvalFlag(rootCmd)
+
+	rootCmd.PersistentFlags().String("config", "", "Config file (default ~/.config/kubectl-switch/config.yaml) (env: KUBECTL_SWITCH_CONFIG)")
+	err := viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+	if err != nil {
+		log.Fatalf("Failed to bind flag: %v", err)
+	}
+
 	rootCmd.PersistentFlags().String("kubeconfig-dir", "", "Directory containing kubeconfig files (env: KUBECONFIG_DIR)")
-	err := viper.BindPFlag("kubeconfig-dir", rootCmd.PersistentFlags().Lookup("kubeconfig-dir"))
+	err = viper.BindPFlag("kubeconfig-dir", rootCmd.PersistentFlags().Lookup("kubeconfig-dir"))
 	if err != nil {
 		log.Fatalf("Failed to bind flag: %v", err)
 	}
@@ -94,4 +120,22 @@ func init() {
 	if err != nil {
 		log.Fatalf("Failed to bind flag: %v", err)
 	}
+
+	rootCmd.PersistentFlags().String("sort", "recent", "Order of entries in selection prompts (name, recent, file) (env: SORT)")
+	err = viper.BindPFlag("sort", rootCmd.PersistentFlags().Lookup("sort"))
+	if err != nil {
+		log.Fatalf("Failed to bind flag: %v", err)
+	}
+
+	rootCmd.PersistentFlags().Bool("terminal-title", false, "Show the active context in the terminal title, and tab color in iTerm2, kitty and tmux, after switching (env: TERMINAL_TITLE)")
+	err = viper.BindPFlag("terminal-title", rootCmd.PersistentFlags().Lookup("terminal-title"))
+	if err != nil {
+		log.Fatalf("Failed to bind flag: %v", err)
+	}
+
+	rootCmd.PersistentFlags().String("request-timeout", "10s", "Timeout for requests to the Kubernetes API, 0 to disable (env: REQUEST_TIMEOUT)")
+	err = viper.BindPFlag("request-timeout", rootCmd.PersistentFlags().Lookup("request-timeout"))
+	if err != nil {
+		log.Fatalf("Failed to bind flag: %v", err)
+	}
 }
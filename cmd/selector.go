@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"maps"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// environmentLabel is the label that holds the environment of a context, unless set explicitly.
+const environmentLabel = "env"
+
+// contextLabelSet returns the labels of a context: those from its kubeconfig extension, overridden
+// by those from the config file, plus its environment tag as env.
+func contextLabelSet(contextName string) labels.Set {
+	set := make(labels.Set)
+	maps.Copy(set, contextInfo(contextName).Labels)
+	maps.Copy(set, contextSettings(contextName).Labels)
+	if environment := contextTag(contextName).Text; environment != "" && !set.Has(environmentLabel) {
+		set[environmentLabel] = environment
+	}
+	return set
+}
+
+// contextSelector parses the label selector given to a command with --selector, which selects
+// every context if it was not given.
+func contextSelector(cmd *cobra.Command) (labels.Selector, error) {
+	selector, _ := cmd.Flags().GetString("selector")
+	return labels.Parse(selector)
+}
+
+// selectContexts returns the contexts whose labels match the selector, keeping their order.
+func selectContexts(contextNames []string, selector labels.Selector) []string {
+	if selector.Empty() {
+		return contextNames
+	}
+	var selected []string
+	for _, contextName := range contextNames {
+		if selector.Matches(contextLabelSet(contextName)) {
+			selected = append(selected, contextName)
+		}
+	}
+	return selected
+}
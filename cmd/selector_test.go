@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// labeledKubeconfig returns a kubeconfig file defining a context of the given name, with the
+// given kubectl-switch extension in flow style.
+func labeledKubeconfig(name, extension string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[1]s.example.com
+users:
+- name: %[1]s
+  user:
+    token: %[1]s-token
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+    extensions:
+    - name: kubectl-switch
+      extension: %[2]s
+current-context: %[1]s
+`, name, extension)
+}
+
+func TestSelectContexts(t *testing.T) {
+	setupContexts(t, map[string]string{
+		"eu-prod": labeledKubeconfig("eu-prod", `{environment: production, labels: {region: eu}}`),
+		"us-prod": labeledKubeconfig("us-prod", `{environment: production, labels: {region: us, team: payments}}`),
+		"dev":     labeledKubeconfig("dev", `{}`),
+		// Invalid labels are dropped, the valid ones kept
+		"broken": labeledKubeconfig("broken", `{labels: {"bad key": x, region: eu, team: "not valid"}}`),
+	}, "dev")
+	loadTestConfig(t, `contexts:
+  - name: us-prod
+    labels:
+      team: orders
+  - name: dev
+    environment: development
+`)
+	contextNames := []string{"broken", "dev", "eu-prod", "us-prod"}
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{"", contextNames},
+		{"region=eu", []string{"broken", "eu-prod"}},
+		{"env=production", []string{"eu-prod", "us-prod"}},
+		{"env=development", []string{"dev"}},
+		{"team=orders", []string{"us-prod"}},
+		{"team=payments", nil},
+		{"team", []string{"us-prod"}},
+		{"!region", []string{"dev"}},
+		{"env in (production,development),region!=us", []string{"dev", "eu-prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := labels.Parse(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := selectContexts(contextNames, selector); !slices.Equal(got, tt.want) {
+				t.Errorf("selectContexts(%q) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/state"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// appState is loaded on first use by loadState.
+var appState *state.State
+
+// loadState returns the persisted state, falling back to an empty one if it cannot be read.
+func loadState() *state.State {
+	if appState == nil {
+		var err error
+		appState, err = state.Load(state.DefaultPath())
+		if err != nil {
+			log.Warnf("Failed to load state: %v", err)
+		}
+	}
+	return appState
+}
+
+// switchContext switches to the given context and, unless namespace is empty, to that namespace
+// of it. See prepareSwitch and completeSwitch for the steps involved.
+func switchContext(cmd *cobra.Command, contextName, namespace string) error {
+	if err := prepareSwitch(cmd, contextName, namespace); err != nil {
+		return err
+	}
+	return completeSwitch(cmd, contextName, namespace)
+}
+
+// prepareSwitch runs the steps of a switch to a context that must happen before its cluster is
+// accessed, e.g. to resolve a namespace: protected contexts are only switched to once the user
+// confirms it, and the pre-switch hooks, which may log in to the cluster, run and abort the switch
+// if they fail. namespace is the namespace as given, if any, since it is not resolved yet.
+func prepareSwitch(cmd *cobra.Command, contextName, namespace string) error {
+	if _, err := evalShell(cmd); err != nil {
+		return err
+	}
+	if err := configManager.ValidateContext(contextName); err != nil {
+		return err
+	}
+	if err := confirmProtected(cmd, contextName); err != nil {
+		return err
+	}
+	return runHooks(cmd.Context(), hookPre, contextName, namespace)
+}
+
+// completeSwitch switches to the given context and, unless namespace is empty, to that namespace
+// of it once prepareSwitch has run, recording the switch for ranking in the pickers. With
+// --verify, the credentials of the context are checked once the post-switch hooks have run.
+func completeSwitch(cmd *cobra.Command, contextName, namespace string) error {
+	shell, err := evalShell(cmd)
+	if err != nil {
+		return err
+	}
+
+	previousContext, previousNamespace := configManager.GetCurrentContext(), configManager.GetCurrentNamespace()
+	if err := configManager.SwitchToContextNamespace(contextName, namespace); err != nil {
+		return err
+	}
+
+	var tag string
+	if t := contextTag(contextName); t.Text != "" {
+		tag = " " + t.String()
+	}
+	if namespace == "" {
+		log.Infof("Switched to context '%s'%s", contextName, tag)
+	} else {
+		log.Infof("Switched to context '%s'%s and namespace '%s'", contextName, tag, namespace)
+	}
+	warnCredentialExpiry(contextName)
+
+	updateTerminalTitle()
+
+	duration, _ := cmd.Flags().GetDuration("for")
+	updateLease(contextName, previousContext, previousNamespace, duration)
+
+	recordUsage(contextName, namespace)
+
+	if err := runHooks(cmd.Context(), hookPost, contextName, namespace); err != nil {
+		log.Warnf("Post-switch hook failed: %v", err)
+	}
+	if verify, _ := cmd.Flags().GetBool("verify"); verify {
+		verifyCredentials(cmd)
+	}
+	return writeEnv(cmd, shell, previousContext, contextName)
+}
+
+// prepareNamespaceSwitch runs the pre-switch hooks of the given context (or the current one)
+// before its cluster is accessed to resolve the namespace to switch to, which is passed to them as
+// given, if any. A failing hook aborts the switch.
+func prepareNamespaceSwitch(cmd *cobra.Command, contextName, namespace string) error {
+	if _, err := evalShell(cmd); err != nil {
+		return err
+	}
+	return runHooks(cmd.Context(), hookPre, contextName, namespace)
+}
+
+// completeNamespaceSwitch switches the namespace of the current context once
+// prepareNamespaceSwitch has run, recording the switch for ranking in the pickers.
+func completeNamespaceSwitch(cmd *cobra.Command, namespace string) error {
+	shell, err := evalShell(cmd)
+	if err != nil {
+		return err
+	}
+	if err := configManager.SwitchToNamespace(namespace); err != nil {
+		return err
+	}
+
+	log.Infof("Switched to namespace '%s'", namespace)
+	updateTerminalTitle()
+
+	recordUsage("", namespace)
+
+	if err := runHooks(cmd.Context(), hookPost, "", namespace); err != nil {
+		log.Warnf("Post-switch hook failed: %v", err)
+	}
+	return writeEnv(cmd, shell, "", "")
+}
+
+// switchBack swaps the active kubeconfig with the previous one. Like any other switch, it asks
+// for confirmation first if that switches to a protected context, runs the hooks of the context
+// around the swap, prints the environment variables of the context with --eval, and is recorded
+// for ranking in the pickers.
+func switchBack(cmd *cobra.Command) error {
+	shell, err := evalShell(cmd)
+	if err != nil {
+		return err
+	}
+
+	previousContext := configManager.GetCurrentContext()
+	contextName, namespace := configManager.GetPreviousContext()
+	if contextName != "" {
+		if err := confirmProtected(cmd, contextName); err != nil {
+			return err
+		}
+		if err := runHooks(cmd.Context(), hookPre, contextName, namespace); err != nil {
+			return err
+		}
+	}
+	if err := configManager.Restore(); err != nil {
+		return err
+	}
+	if err := writeEnv(cmd, shell, previousContext, contextName); err != nil {
+		return err
+	}
+
+	updateTerminalTitle()
+	if contextName == "" {
+		return nil
+	}
+	recordUsage(contextName, namespace)
+	if err := runHooks(cmd.Context(), hookPost, contextName, namespace); err != nil {
+		log.Warnf("Post-switch hook failed: %v", err)
+	}
+	return nil
+}
+
+// recordUsage notes a switch to the given context (or the current one) and namespace, if any.
+func recordUsage(contextName, namespace string) {
+	s := loadState()
+	now := time.Now()
+	if contextName != "" {
+		s.RecordContext(contextName, now)
+	} else {
+		contextName = configManager.GetCurrentContext()
+	}
+	if namespace != "" {
+		s.RecordNamespace(contextName, namespace, now)
+	}
+
+	if err := s.Save(); err != nil {
+		log.Warnf("Failed to save state: %v", err)
+	}
+}
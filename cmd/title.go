@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/mirceanton/kubectl-switch/v2/internal/ui"
+	log "github.com/sirupsen/logrus"
+)
+
+// updateTerminalTitle shows the active context and namespace in the terminal title, and colors
+// the terminal tab in the color of the context's environment tag, if enabled in the config.
+func updateTerminalTitle() {
+	if !appConfig.TerminalTitle {
+		return
+	}
+
+	var title, color string
+	if contextName := configManager.GetCurrentContext(); contextName != "" {
+		title = contextName
+		if label := contextLabel(contextName); label != "" {
+			title = label
+		}
+		if namespace := configManager.GetCurrentNamespace(); namespace != "" {
+			title += ":" + namespace
+		}
+		tag := contextTag(contextName)
+		if tag.Text != "" {
+			title += " " + tag.String()
+		}
+		color = tag.Color
+	}
+
+	if err := ui.SetTerminalTitle(title, color); err != nil {
+		log.Debugf("Failed to update the terminal title: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Print the user the credentials of a context authenticate as",
+	Long: `Whoami asks the cluster of the current context, or of the one given with --context, which user
+its credentials map to, using the SelfSubjectReview API (Kubernetes 1.27 or later), and prints its
+username, UID, groups and extra attributes.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "table" && output != "json" {
+			log.Fatalf("Invalid output format: %s", output)
+		}
+
+		contextName, _ := cmd.Flags().GetString("context")
+		if contextName != "" {
+			if err := configManager.LoadContexts(); err != nil {
+				log.Fatalf("Failed to load contexts: %v", err)
+			}
+			contextName = expandContextAlias(contextName, configManager.GetAllContexts())
+		}
+
+		identity, err := configManager.WhoAmI(cmd.Context(), contextName)
+		if err != nil {
+			log.Fatalf("Failed to get user info: %v", err)
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(identity); err != nil {
+				log.Fatalf("Failed to write output: %v", err)
+			}
+			return
+		}
+
+		out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(out, "ATTRIBUTE\tVALUE")
+		_, _ = fmt.Fprintf(out, "Username\t%s\n", identity.Username)
+		if identity.UID != "" {
+			_, _ = fmt.Fprintf(out, "UID\t%s\n", identity.UID)
+		}
+		if len(identity.Groups) > 0 {
+			_, _ = fmt.Fprintf(out, "Groups\t%s\n", strings.Join(identity.Groups, ", "))
+		}
+		for _, key := range slices.Sorted(maps.Keys(identity.Extra)) {
+			_, _ = fmt.Fprintf(out, "Extra: %s\t%s\n", key, strings.Join(identity.Extra[key], ", "))
+		}
+		if err := out.Flush(); err != nil {
+			log.Fatalf("Failed to write output: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+
+	whoamiCmd.Flags().String("context", "", "Context to check instead of the current one")
+	whoamiCmd.Flags().StringP("output", "o", "table", "Output format (table, json)")
+
+	err := whoamiCmd.RegisterFlagCompletionFunc("context", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if err := configManager.LoadContexts(); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return withAliases(configManager.GetAllContexts(), appConfig.ContextAliases), cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatalf("Failed to register flag completion: %v", err)
+	}
+}
+
+// verifyCredentials checks that the credentials of the active kubeconfig are accepted by the
+// cluster, warning if they are not.
+func verifyCredentials(cmd *cobra.Command) {
+	identity, err := configManager.WhoAmI(cmd.Context(), "")
+	if err != nil {
+		log.Warnf("Failed to verify credentials: %v", err)
+		return
+	}
+	log.Infof("Authenticated as '%s'", identity.Username)
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/yamledit"
+	"go.yaml.in/yaml/v3"
+)
+
+// Kinds of aliases
+const (
+	AliasContext   = "contexts"
+	AliasNamespace = "namespaces"
+)
+
+// Aliases holds alternative names for contexts and namespaces.
+type Aliases struct {
+	Contexts   []Alias `mapstructure:"contexts"`
+	Namespaces []Alias `mapstructure:"namespaces"`
+}
+
+// Alias maps an alternative name to the real name of a context or namespace.
+type Alias struct {
+	Name   string `mapstructure:"name"`
+	Target string `mapstructure:"target"`
+}
+
+// ResolveContextAlias returns the context the given alias stands for.
+func (c *Config) ResolveContextAlias(alias string) (string, bool) {
+	return resolveAlias(c.Aliases.Contexts, alias)
+}
+
+// ResolveNamespaceAlias returns the namespace the given alias stands for.
+func (c *Config) ResolveNamespaceAlias(alias string) (string, bool) {
+	return resolveAlias(c.Aliases.Namespaces, alias)
+}
+
+// ContextAliases returns the aliases defined for the given context.
+func (c *Config) ContextAliases(contextName string) []string {
+	return aliasesFor(c.Aliases.Contexts, contextName)
+}
+
+// NamespaceAliases returns the aliases defined for the given namespace.
+func (c *Config) NamespaceAliases(namespace string) []string {
+	return aliasesFor(c.Aliases.Namespaces, namespace)
+}
+
+func resolveAlias(aliases []Alias, name string) (string, bool) {
+	for _, alias := range aliases {
+		if alias.Name == name {
+			return alias.Target, true
+		}
+	}
+	return "", false
+}
+
+func aliasesFor(aliases []Alias, target string) []string {
+	var names []string
+	for _, alias := range aliases {
+		if alias.Target == target {
+			names = append(names, alias.Name)
+		}
+	}
+	return names
+}
+
+// ValidateAliasName checks that an alias can be told apart from other arguments.
+func ValidateAliasName(name string) error {
+	if name == "" {
+		return fmt.Errorf("alias name cannot be empty")
+	}
+	if strings.ContainsAny(name, ": \t") {
+		return fmt.Errorf("alias name '%s' cannot contain colons or whitespace", name)
+	}
+	return nil
+}
+
+// SetAlias adds or replaces an alias of the given kind in the config file.
+func (c *Config) SetAlias(kind, name, target string) error {
+	return c.editAliases(kind, func(aliases []*yaml.Node) []*yaml.Node {
+		aliases = slices.DeleteFunc(aliases, aliasNamed(name))
+		return append(aliases, &yaml.Node{
+			Kind: yaml.MappingNode,
+			Content: []*yaml.Node{
+				yamledit.String("name"), yamledit.String(name),
+				yamledit.String("target"), yamledit.String(target),
+			},
+		})
+	})
+}
+
+// RemoveAlias removes an alias of the given kind from the config file.
+func (c *Config) RemoveAlias(kind, name string) error {
+	if _, found := resolveAlias(c.aliasesOfKind(kind), name); !found {
+		return fmt.Errorf("alias '%s' not found", name)
+	}
+	return c.editAliases(kind, func(aliases []*yaml.Node) []*yaml.Node {
+		return slices.DeleteFunc(aliases, aliasNamed(name))
+	})
+}
+
+func (c *Config) aliasesOfKind(kind string) []Alias {
+	if kind == AliasNamespace {
+		return c.Aliases.Namespaces
+	}
+	return c.Aliases.Contexts
+}
+
+// editAliases rewrites the list of aliases of the given kind in the config file, keeping the rest
+// of the file (including comments) intact.
+func (c *Config) editAliases(kind string, edit func([]*yaml.Node) []*yaml.Node) error {
+	if c.File == "" {
+		return fmt.Errorf("no config file location available")
+	}
+
+	data, err := os.ReadFile(c.File)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	doc, root, err := yamledit.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	aliases := yamledit.Value(root, keyAliases, yaml.MappingNode)
+	list := yamledit.Value(aliases, kind, yaml.SequenceNode)
+	list.Content = edit(list.Content)
+	list.Style = 0
+
+	out, err := yamledit.Encode(doc, data)
+	if err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.File), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(c.File, out, 0o644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// aliasNamed matches alias entries in the config file by name.
+func aliasNamed(name string) func(*yaml.Node) bool {
+	return func(node *yaml.Node) bool {
+		value := yamledit.Lookup(node, "name")
+		return value != nil && value.Value == name
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSetAlias(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial string
+		kind    string
+		alias   string
+		target  string
+		want    string
+	}{
+		{
+			name:   "missing file",
+			kind:   AliasContext,
+			alias:  "prod",
+			target: "payments-prod",
+			want: `aliases:
+  contexts:
+    - name: prod
+      target: payments-prod
+`,
+		},
+		{
+			name: "keeps comments and other settings",
+			initial: `# kubectl-switch settings
+sort: name # alphabetical
+aliases:
+  namespaces:
+    - name: pay
+      target: payments
+`,
+			kind:   AliasContext,
+			alias:  "prod",
+			target: "payments-prod",
+			want: `# kubectl-switch settings
+sort: name # alphabetical
+aliases:
+  namespaces:
+    - name: pay
+      target: payments
+  contexts:
+    - name: prod
+      target: payments-prod
+`,
+		},
+		{
+			name: "replaces an existing alias",
+			initial: `aliases:
+  contexts:
+    - name: prod
+      target: orders-prod
+    - name: stage
+      target: orders-staging
+`,
+			kind:   AliasContext,
+			alias:  "prod",
+			target: "payments-prod",
+			want: `aliases:
+  contexts:
+    - name: stage
+      target: orders-staging
+    - name: prod
+      target: payments-prod
+`,
+		},
+		{
+			name:   "quotes values that are not plain strings",
+			kind:   AliasNamespace,
+			alias:  "true",
+			target: "0123",
+			want: `aliases:
+  namespaces:
+    - name: "true"
+      target: "0123"
+`,
+		},
+		{
+			name: "keeps sequences that are not indented",
+			initial: `aliases:
+  contexts:
+  - name: stage
+    target: payments-staging
+`,
+			kind:   AliasContext,
+			alias:  "prod",
+			target: "payments-prod",
+			want: `aliases:
+  contexts:
+  - name: stage
+    target: payments-staging
+  - name: prod
+    target: payments-prod
+`,
+		},
+		{
+			name: "expands a flow style list",
+			initial: `aliases:
+  namespaces: []
+`,
+			kind:   AliasNamespace,
+			alias:  "pay",
+			target: "payments",
+			want: `aliases:
+  namespaces:
+    - name: pay
+      target: payments
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{File: filepath.Join(t.TempDir(), "config.yaml")}
+			if tt.initial != "" {
+				if err := os.WriteFile(c.File, []byte(tt.initial), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := c.SetAlias(tt.kind, tt.alias, tt.target); err != nil {
+				t.Fatalf("SetAlias() failed: %v", err)
+			}
+			got, err := os.ReadFile(c.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("config file =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			v := viper.New()
+			v.SetConfigFile(c.File)
+			if err := v.ReadInConfig(); err != nil {
+				t.Fatalf("ReadInConfig() failed: %v", err)
+			}
+			var aliases Aliases
+			if err := v.UnmarshalKey(keyAliases, &aliases); err != nil {
+				t.Fatalf("UnmarshalKey() failed: %v", err)
+			}
+			written := (&Config{Aliases: aliases}).aliasesOfKind(tt.kind)
+			if target, _ := resolveAlias(written, tt.alias); target != tt.target {
+				t.Errorf("alias '%s' reads back as '%s', want '%s'", tt.alias, target, tt.target)
+			}
+		})
+	}
+}
+
+func TestRemoveAlias(t *testing.T) {
+	initial := `aliases:
+  contexts:
+    # the one in use
+    - name: prod
+      target: payments-prod
+    - name: stage
+      target: payments-staging
+`
+
+	tests := []struct {
+		name    string
+		alias   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "removes the alias",
+			alias: "stage",
+			want: `aliases:
+  contexts:
+    # the one in use
+    - name: prod
+      target: payments-prod
+`,
+		},
+		{
+			name:    "unknown alias",
+			alias:   "dev",
+			want:    initial,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{
+				File: filepath.Join(t.TempDir(), "config.yaml"),
+				Aliases: Aliases{Contexts: []Alias{
+					{Name: "prod", Target: "payments-prod"},
+					{Name: "stage", Target: "payments-staging"},
+				}},
+			}
+			if err := os.WriteFile(c.File, []byte(initial), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := c.RemoveAlias(AliasContext, tt.alias)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RemoveAlias() error = %v, want error %v", err, tt.wantErr)
+			}
+			got, err := os.ReadFile(c.File)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("config file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// colorNames maps color names to their ANSI color numbers.
+var colorNames = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+	"grey":    "8",
+}
+
+// environmentColors are the colors of well-known environments that have no color configured.
+var environmentColors = map[string]string{
+	"production":  "red",
+	"prod":        "red",
+	"staging":     "yellow",
+	"stage":       "yellow",
+	"development": "green",
+	"dev":         "green",
+}
+
+var hexColorRegexp = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ParseColor converts a color name, ANSI color number (0-255) or hex code (#rrggbb) into an ANSI
+// color number or hex code. An empty color is returned as is.
+func ParseColor(color string) (string, error) {
+	if color == "" || hexColorRegexp.MatchString(color) {
+		return color, nil
+	}
+	if number, found := colorNames[strings.ToLower(color)]; found {
+		return number, nil
+	}
+	if n, err := strconv.Atoi(color); err == nil && n >= 0 && n <= 255 {
+		return color, nil
+	}
+	return "", fmt.Errorf("unknown color '%s' (use a name, 0-255 or #rrggbb)", color)
+}
+
+// EnvironmentColor returns the color to show an environment tag in: the given color if valid,
+// otherwise the default color of well-known environments.
+func EnvironmentColor(environment, color string) string {
+	if parsed, err := ParseColor(color); err == nil && parsed != "" {
+		return parsed
+	}
+	parsed, _ := ParseColor(environmentColors[strings.ToLower(environment)])
+	return parsed
+}
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -13,25 +17,95 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	KubeconfigDir string
-	Kubeconfig    string
-	LogLevel      log.Level
-	LogFormat     log.Formatter
-	PageSize      int
+	// File is the path of the config file, which does not necessarily exist
+	File string
+
+	KubeconfigDir  string
+	Kubeconfig     string
+	LogLevel       log.Level
+	LogFormat      log.Formatter
+	PageSize       int
+	RequestTimeout time.Duration
+
+	// Namespace picker columns
+	NamespaceLabelColumns []string
+	NamespacePods         bool
+
+	// Picker ordering
+	Sort               string
+	FavoriteContexts   []string
+	FavoriteNamespaces []string
+
+	// Compiled favorite patterns
+	favoriteContexts   []*regexp.Regexp
+	favoriteNamespaces []*regexp.Regexp
+
+	// Commands to run before and after every switch
+	PreSwitchHooks  []Hook
+	PostSwitchHooks []Hook
+
+	// Environments whose contexts require confirmation before switching to them
+	ProtectedEnvironments []string
+
+	// Whether to show the active context in the terminal title and tab color
+	TerminalTitle bool
+
+	// How long before credentials expire to start warning about it
+	CredentialExpiryWarning time.Duration
+
+	// How contexts are shown in the picker and list
+	ContextTemplate *template.Template
+	ContextRewrites []Rewrite
+
+	// Per-context settings from the config file
+	Contexts []ContextConfig
+	Aliases  Aliases
+	Groups   []Group
 }
 
 const (
 	// Configuration keys
-	keyKubeconfigDir = "kubeconfig-dir"
-	keyKubeconfig    = "kubeconfig"
-	keyLogLevel      = "log-level"
-	keyLogFormat     = "log-format"
-	keyPageSize      = "page-size"
+	keyConfig         = "config"
+	keyKubeconfigDir  = "kubeconfig-dir"
+	keyKubeconfig     = "kubeconfig"
+	keyLogLevel       = "log-level"
+	keyLogFormat      = "log-format"
+	keyPageSize       = "page-size"
+	keyRequestTimeout = "request-timeout"
+
+	keyNamespaceLabelColumns = "namespace-label-columns"
+	keyNamespacePods         = "namespace-pods"
+	keyContexts              = "contexts"
+	keySort                  = "sort"
+	keyFavoriteContexts      = "favorite-contexts"
+	keyFavoriteNamespaces    = "favorite-namespaces"
+	keyAliases               = "aliases"
+	keyContextTemplate       = "context-template"
+	keyContextRewrites       = "context-rewrites"
+	keyProtectedEnvironments = "protected-environments"
+	keyGroups                = "groups"
+	keyPreSwitchHooks        = "pre-switch-hooks"
+	keyPostSwitchHooks       = "post-switch-hooks"
+	keyTerminalTitle         = "terminal-title"
+	keyCredentialExpiry      = "credential-expiry-warning"
+
+	// Environment variable for the config file path, which is too generic to derive from the key
+	envConfig = "KUBECTL_SWITCH_CONFIG"
 
 	// Default values
-	defaultLogLevel  = "info"
-	defaultLogFormat = "text"
-	defaultPageSize  = 10
+	defaultLogLevel       = "info"
+	defaultLogFormat      = "text"
+	defaultPageSize       = 10
+	defaultRequestTimeout = 10 * time.Second
+	defaultSort           = SortRecent
+	defaultExpiryWarning  = 7 * 24 * time.Hour
+)
+
+// Sort orders for the selection prompts
+const (
+	SortName   = "name"
+	SortRecent = "recent"
+	SortFile   = "file"
 )
 
 var (
@@ -47,6 +121,9 @@ func Init() {
 	// Replace hyphens with underscores in env vars
 	// This allows --kubeconfig-dir to map to KUBECONFIG_DIR
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	if err := viper.BindEnv(keyConfig, envConfig); err != nil {
+		log.Fatalf("Failed to bind environment variable: %v", err)
+	}
 
 	// Set default values
 	viper.SetDefault(keyKubeconfigDir, defaultKubeconfigDir)
@@ -54,12 +131,25 @@ func Init() {
 	viper.SetDefault(keyLogLevel, defaultLogLevel)
 	viper.SetDefault(keyLogFormat, defaultLogFormat)
 	viper.SetDefault(keyPageSize, defaultPageSize)
+	viper.SetDefault(keyRequestTimeout, defaultRequestTimeout)
+	viper.SetDefault(keyNamespaceLabelColumns, []string{})
+	viper.SetDefault(keyNamespacePods, false)
+	viper.SetDefault(keySort, defaultSort)
+	viper.SetDefault(keyTerminalTitle, false)
+	viper.SetDefault(keyCredentialExpiry, defaultExpiryWarning)
 }
 
 // Load returns the current configuration
 func Load() (*Config, error) {
 	cfg := &Config{}
 
+	// Read the config file, if there is one
+	var err error
+	cfg.File, err = readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse log level
 	levelStr := viper.GetString(keyLogLevel)
 	level, err := log.ParseLevel(levelStr)
@@ -101,9 +191,135 @@ func Load() (*Config, error) {
 	// Get page size
 	cfg.PageSize = viper.GetInt(keyPageSize)
 
+	// Parse request timeout
+	timeoutStr := viper.GetString(keyRequestTimeout)
+	cfg.RequestTimeout, err = time.ParseDuration(timeoutStr)
+	if err != nil || cfg.RequestTimeout < 0 {
+		return nil, fmt.Errorf("invalid request timeout: %s", timeoutStr)
+	}
+
+	// Get namespace picker columns
+	cfg.NamespaceLabelColumns = splitList(viper.GetStringSlice(keyNamespaceLabelColumns))
+	cfg.NamespacePods = viper.GetBool(keyNamespacePods)
+
+	// Get picker ordering
+	cfg.Sort = strings.ToLower(viper.GetString(keySort))
+	switch cfg.Sort {
+	case SortName, SortRecent, SortFile:
+	default:
+		return nil, fmt.Errorf("invalid sort order: %s", cfg.Sort)
+	}
+	cfg.FavoriteContexts = splitList(viper.GetStringSlice(keyFavoriteContexts))
+	cfg.FavoriteNamespaces = splitList(viper.GetStringSlice(keyFavoriteNamespaces))
+	cfg.favoriteContexts = compileGlobs(cfg.FavoriteContexts)
+	cfg.favoriteNamespaces = compileGlobs(cfg.FavoriteNamespaces)
+
+	// Get global hooks
+	if err := viper.UnmarshalKey(keyPreSwitchHooks, &cfg.PreSwitchHooks); err != nil {
+		return nil, fmt.Errorf("invalid pre-switch hooks configuration: %w", err)
+	}
+	if err := viper.UnmarshalKey(keyPostSwitchHooks, &cfg.PostSwitchHooks); err != nil {
+		return nil, fmt.Errorf("invalid post-switch hooks configuration: %w", err)
+	}
+	if err := validateHooks(slices.Concat(cfg.PreSwitchHooks, cfg.PostSwitchHooks)); err != nil {
+		return nil, fmt.Errorf("invalid hooks configuration: %w", err)
+	}
+
+	// Get protected environments
+	cfg.ProtectedEnvironments = splitList(viper.GetStringSlice(keyProtectedEnvironments))
+
+	// Get terminal title settings
+	cfg.TerminalTitle = viper.GetBool(keyTerminalTitle)
+
+	// Parse credential expiry warning
+	expiryStr := viper.GetString(keyCredentialExpiry)
+	cfg.CredentialExpiryWarning, err = time.ParseDuration(expiryStr)
+	if err != nil || cfg.CredentialExpiryWarning < 0 {
+		return nil, fmt.Errorf("invalid credential expiry warning: %s", expiryStr)
+	}
+
+	// Get context display settings
+	cfg.ContextTemplate, err = parseContextTemplate(viper.GetString(keyContextTemplate))
+	if err != nil {
+		return nil, err
+	}
+	if err := viper.UnmarshalKey(keyContextRewrites, &cfg.ContextRewrites); err != nil {
+		return nil, fmt.Errorf("invalid context rewrites configuration: %w", err)
+	}
+	for i := range cfg.ContextRewrites {
+		if err := cfg.ContextRewrites[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get per-context settings
+	if err := viper.UnmarshalKey(keyContexts, &cfg.Contexts); err != nil {
+		return nil, fmt.Errorf("invalid contexts configuration: %w", err)
+	}
+	for i := range cfg.Contexts {
+		if err := cfg.Contexts[i].validate(); err != nil {
+			return nil, err
+		}
+		cfg.Contexts[i].compile()
+	}
+
+	// Get aliases
+	if err := viper.UnmarshalKey(keyAliases, &cfg.Aliases); err != nil {
+		return nil, fmt.Errorf("invalid aliases configuration: %w", err)
+	}
+
+	// Get context groups
+	if err := viper.UnmarshalKey(keyGroups, &cfg.Groups); err != nil {
+		return nil, fmt.Errorf("invalid groups configuration: %w", err)
+	}
+	for _, group := range cfg.Groups {
+		if err := group.validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	return cfg, nil
 }
 
+// readConfigFile reads the config file given via --config, or the default one if it exists,
+// and returns its path.
+func readConfigFile() (string, error) {
+	path, explicit, err := configFilePath()
+	if err != nil || path == "" {
+		return path, err
+	}
+	if _, err := os.Stat(path); err != nil && !explicit && os.IsNotExist(err) {
+		return path, nil
+	}
+
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	log.Debugf("Using config file %s", path)
+	return path, nil
+}
+
+// configFilePath returns the path of the config file and whether it was given explicitly.
+func configFilePath() (string, bool, error) {
+	path := viper.GetString(keyConfig)
+	explicit := path != ""
+	if !explicit {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			// Without a home directory, there is no default config file
+			return "", false, nil
+		}
+		path = filepath.Join(configDir, "kubectl-switch", "config.yaml")
+	}
+
+	path, err := expandPath(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to expand config file path: %w", err)
+	}
+	return path, explicit, nil
+}
+
 // validateKubeconfigDir validates that the kubeconfig directory exists and is a directory
 func (c *Config) validateKubeconfigDir() error {
 	info, err := os.Stat(c.KubeconfigDir)
@@ -151,3 +367,17 @@ func expandPath(path string) (string, error) {
 
 	return filepath.Join(homeDir, path[2:]), nil
 }
+
+// splitList splits comma-separated entries, so that lists can be given as a single environment
+// variable as well as through repeated flags.
+func splitList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
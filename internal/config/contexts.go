@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ContextConfig holds settings for the contexts matching all of its Name, Server and File
+// patterns, of which at least one must be given. When several entries match a context, later
+// entries take precedence.
+type ContextConfig struct {
+	// Name is a glob pattern matched against the full context name, where * matches any
+	// sequence of characters (including /) and ? matches a single character
+	Name string `mapstructure:"name"`
+	// Server is a glob pattern matched against the API server URL of the context's cluster
+	Server string `mapstructure:"server"`
+	// File is a glob pattern matched against the name of the kubeconfig file defining the context
+	File string `mapstructure:"file"`
+	// Environment tags the contexts, e.g. as production
+	Environment string `mapstructure:"environment"`
+	// Color is the color the environment tag is shown in
+	Color string `mapstructure:"color"`
+	// Protected contexts require confirmation before switching to them
+	Protected *bool `mapstructure:"protected"`
+	// Labels are key/value pairs that contexts can be selected by
+	Labels map[string]string `mapstructure:"labels"`
+	// Env lists environment variables to set for the contexts, as NAME=value
+	Env []string `mapstructure:"env"`
+	// PreSwitchHooks run before switching to the contexts (or their namespaces), in addition to
+	// the global ones
+	PreSwitchHooks []Hook `mapstructure:"pre-switch-hooks"`
+	// PostSwitchHooks run after switching to the contexts (or their namespaces), in addition to
+	// the global ones
+	PostSwitchHooks []Hook `mapstructure:"post-switch-hooks"`
+	// NamespaceSelector is the label selector applied when listing namespaces
+	NamespaceSelector string `mapstructure:"namespace-selector"`
+	// FavoriteNamespaces are pinned to the top of the namespace picker, in addition to the
+	// globally configured ones
+	FavoriteNamespaces []string `mapstructure:"favorite-namespaces"`
+
+	// Compiled patterns, set by compile
+	name, server, file *regexp.Regexp
+	favoriteNamespaces []*regexp.Regexp
+}
+
+// ContextRef identifies a context for matching it against the config entries.
+type ContextRef struct {
+	Name string
+	// Server is the API server URL of the context's cluster
+	Server string
+	// File is the name of the kubeconfig file defining the context
+	File string
+}
+
+// ContextSettings holds the effective settings for a single context.
+type ContextSettings struct {
+	NamespaceSelector string
+	Environment       string
+	Color             string
+	// Protected is nil unless an entry sets whether the context is protected
+	Protected *bool
+	Labels    map[string]string
+	Env       map[string]string
+
+	// Hooks to run around switches, the global ones first
+	PreSwitchHooks  []Hook
+	PostSwitchHooks []Hook
+
+	favoriteNamespaces []*regexp.Regexp
+}
+
+// ForContext merges the settings of all config entries that match the given context.
+func (c *Config) ForContext(ref ContextRef) ContextSettings {
+	settings := ContextSettings{
+		PreSwitchHooks:     slices.Clone(c.PreSwitchHooks),
+		PostSwitchHooks:    slices.Clone(c.PostSwitchHooks),
+		favoriteNamespaces: slices.Clone(c.favoriteNamespaces),
+	}
+	for _, ctx := range c.Contexts {
+		if !ctx.Matches(ref) {
+			continue
+		}
+		if ctx.NamespaceSelector != "" {
+			settings.NamespaceSelector = ctx.NamespaceSelector
+		}
+		if ctx.Environment != "" {
+			settings.Environment = ctx.Environment
+		}
+		if ctx.Color != "" {
+			settings.Color = ctx.Color
+		}
+		if ctx.Protected != nil {
+			settings.Protected = ctx.Protected
+		}
+		settings.PreSwitchHooks = append(settings.PreSwitchHooks, ctx.PreSwitchHooks...)
+		settings.PostSwitchHooks = append(settings.PostSwitchHooks, ctx.PostSwitchHooks...)
+		for _, variable := range ctx.Env {
+			if settings.Env == nil {
+				settings.Env = make(map[string]string)
+			}
+			name, value, _ := strings.Cut(variable, "=")
+			settings.Env[name] = value
+		}
+		for key, value := range ctx.Labels {
+			if settings.Labels == nil {
+				settings.Labels = make(map[string]string)
+			}
+			settings.Labels[key] = value
+		}
+		settings.favoriteNamespaces = append(settings.favoriteNamespaces, ctx.favoriteNamespaces...)
+	}
+	return settings
+}
+
+// IsProtectedEnvironment reports whether contexts tagged with the given environment are protected.
+func (c *Config) IsProtectedEnvironment(environment string) bool {
+	return environment != "" && slices.ContainsFunc(c.ProtectedEnvironments, func(protected string) bool {
+		return strings.EqualFold(protected, environment)
+	})
+}
+
+// IsFavoriteContext reports whether the given context matches one of the favorite patterns.
+func (c *Config) IsFavoriteContext(name string) bool {
+	return matchesAny(c.favoriteContexts, name)
+}
+
+// IsFavoriteNamespace reports whether the given namespace matches one of the favorite patterns.
+func (s ContextSettings) IsFavoriteNamespace(name string) bool {
+	return matchesAny(s.favoriteNamespaces, name)
+}
+
+// Matches reports whether the entry applies to the given context.
+func (c ContextConfig) Matches(ref ContextRef) bool {
+	return (c.Name == "" || c.name.MatchString(ref.Name)) &&
+		(c.Server == "" || c.server.MatchString(ref.Server)) &&
+		(c.File == "" || c.file.MatchString(ref.File))
+}
+
+// compile prepares the patterns of an entry for matching.
+func (c *ContextConfig) compile() {
+	c.name = globRegexp(c.Name)
+	c.server = globRegexp(c.Server)
+	c.file = globRegexp(c.File)
+	c.favoriteNamespaces = compileGlobs(c.FavoriteNamespaces)
+}
+
+// validate checks an entry for mistakes that would otherwise only surface when it is used.
+func (c ContextConfig) validate() error {
+	if c.Name == "" && c.Server == "" && c.File == "" {
+		return fmt.Errorf("invalid contexts configuration: entry without a name, server or file")
+	}
+	if _, err := labels.Parse(c.NamespaceSelector); err != nil {
+		return fmt.Errorf("invalid namespace selector for '%s': %w", c.describe(), err)
+	}
+	if _, err := ParseColor(c.Color); err != nil {
+		return fmt.Errorf("invalid color for '%s': %w", c.describe(), err)
+	}
+	if _, err := labels.ValidatedSelectorFromSet(c.Labels); err != nil {
+		return fmt.Errorf("invalid labels for '%s': %w", c.describe(), err)
+	}
+	for _, variable := range c.Env {
+		if name, _, found := strings.Cut(variable, "="); !found || !IsEnvName(name) {
+			return fmt.Errorf("invalid environment variable for '%s': expected NAME=value, got '%s'", c.describe(), variable)
+		}
+	}
+	if err := validateHooks(slices.Concat(c.PreSwitchHooks, c.PostSwitchHooks)); err != nil {
+		return fmt.Errorf("invalid hooks for '%s': %w", c.describe(), err)
+	}
+	return nil
+}
+
+// describe names an entry in error messages by its patterns.
+func (c ContextConfig) describe() string {
+	var patterns []string
+	for _, pattern := range []string{c.Name, c.Server, c.File} {
+		if pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return strings.Join(patterns, ", ")
+}
+
+// envNameRegexp matches valid environment variable names.
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsEnvName reports whether name is a valid environment variable name, which shells can set.
+func IsEnvName(name string) bool {
+	return envNameRegexp.MatchString(name)
+}
+
+// compileGlobs converts glob patterns into regular expressions with globRegexp.
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	globs := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		globs[i] = globRegexp(pattern)
+	}
+	return globs
+}
+
+// matchesAny reports whether name matches one of the compiled glob patterns.
+func matchesAny(globs []*regexp.Regexp, name string) bool {
+	return slices.ContainsFunc(globs, func(glob *regexp.Regexp) bool {
+		return glob.MatchString(name)
+	})
+}
+
+// globRegexp converts a glob pattern into an anchored regular expression, where * matches any
+// sequence of characters (including /) and ? matches a single character.
+func globRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
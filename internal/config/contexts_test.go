@@ -0,0 +1,60 @@
+package config
+
+import "testing"
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"prod", "prod", true},
+		{"prod", "prod-eu", false},
+		{"prod*", "prod-eu", true},
+		{"*prod*", "payments-prod-eu", true},
+		{"*prod", "prod", true},
+		{"arn:aws:eks:*", "arn:aws:eks:eu-west-1:123456789012:cluster/payments", true},
+		{"*/payments", "arn:aws:eks:eu-west-1:123456789012:cluster/payments", true},
+		{"prod-??", "prod-eu", true},
+		{"prod-??", "prod-eu1", false},
+		{"*.yaml", "prod.yaml", true},
+		{"*.yaml", "prodxyaml", false},
+		{"https://10.0.0.?:6443", "https://10.0.0.1:6443", true},
+		{"(prod)+", "(prod)+", true},
+		{"(prod)+", "prodprod", false},
+		{"", "", true},
+		{"", "prod", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.name, func(t *testing.T) {
+			if got := globRegexp(tt.pattern).MatchString(tt.name); got != tt.want {
+				t.Errorf("globRegexp(%q) matching %q = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextConfigMatches(t *testing.T) {
+	ref := ContextRef{Name: "payments-prod", Server: "https://prod.example.com", File: "payments.yaml"}
+
+	tests := []struct {
+		name  string
+		entry ContextConfig
+		want  bool
+	}{
+		{"name", ContextConfig{Name: "payments-*"}, true},
+		{"other name", ContextConfig{Name: "orders-*"}, false},
+		{"server", ContextConfig{Server: "https://prod.*"}, true},
+		{"file", ContextConfig{File: "*.yaml"}, true},
+		{"all", ContextConfig{Name: "*-prod", Server: "*example.com", File: "payments.yaml"}, true},
+		{"one mismatch", ContextConfig{Name: "*-prod", File: "orders.yaml"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entry.compile()
+			if got := tt.entry.Matches(ref); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Rewrite is a regular expression replacement applied to context names before they are displayed.
+type Rewrite struct {
+	Pattern string `mapstructure:"pattern"`
+	// Replace may refer to capture groups as $1 or ${name}
+	Replace string `mapstructure:"replace"`
+
+	regexp *regexp.Regexp
+}
+
+// ContextDisplay holds the fields available to the context display template.
+type ContextDisplay struct {
+	// Name is the real name of the context
+	Name string
+	// Short is the name after applying the configured rewrites
+	Short string
+	// Alias is the first alias of the context, if any
+	Alias   string
+	Aliases []string
+	Cluster string
+	User    string
+	// Namespace is the namespace set for the context in its kubeconfig file
+	Namespace string
+	// File is the name of the kubeconfig file the context is defined in
+	File string
+	// Path is the full path of that kubeconfig file
+	Path string
+}
+
+// templateFuncs are the functions available to the context display template.
+var templateFuncs = template.FuncMap{
+	// default returns value unless it is empty, in which case it returns fallback, so that
+	// {{.Alias | default .Name}} falls back to the name
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"join":       func(sep string, values []string) string { return strings.Join(values, sep) },
+	"base":       filepath.Base,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+}
+
+// parseContextTemplate parses the context display template, returning nil if none is configured.
+func parseContextTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("context").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// compile prepares a rewrite rule for use, rejecting invalid patterns.
+func (r *Rewrite) compile() error {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid context rewrite pattern '%s': %w", r.Pattern, err)
+	}
+	r.regexp = re
+	return nil
+}
+
+// HasContextDisplay reports whether contexts are displayed differently from their names.
+func (c *Config) HasContextDisplay() bool {
+	return c.ContextTemplate != nil || len(c.ContextRewrites) > 0
+}
+
+// RewriteContextName applies the configured rewrite rules to a context name, in order.
+func (c *Config) RewriteContextName(name string) string {
+	for _, rewrite := range c.ContextRewrites {
+		name = rewrite.regexp.ReplaceAllString(name, rewrite.Replace)
+	}
+	return name
+}
+
+// DisplayContext renders a context with the configured template, or returns its rewritten name
+// if there is no template. Should the template fail, the rewritten name is used as well.
+func (c *Config) DisplayContext(display ContextDisplay) string {
+	if c.ContextTemplate == nil {
+		return display.Short
+	}
+
+	var b strings.Builder
+	if err := c.ContextTemplate.Execute(&b, display); err != nil {
+		log.Debugf("Failed to render context '%s': %v", display.Name, err)
+		return display.Short
+	}
+	return b.String()
+}
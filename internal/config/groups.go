@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Group is a named set of contexts, listed explicitly, selected by their labels, or both.
+type Group struct {
+	Name string `mapstructure:"name"`
+	// Contexts are the names (or aliases) of the group's contexts, in the order to cycle through them
+	Contexts []string `mapstructure:"contexts"`
+	// Selector adds the contexts whose labels match it, after the listed ones
+	Selector string `mapstructure:"selector"`
+}
+
+// Group returns the group with the given name.
+func (c *Config) Group(name string) (Group, error) {
+	for _, group := range c.Groups {
+		if group.Name == name {
+			return group, nil
+		}
+	}
+	return Group{}, fmt.Errorf("group '%s' not found", name)
+}
+
+// GroupNames returns the names of all configured groups.
+func (c *Config) GroupNames() []string {
+	names := make([]string, len(c.Groups))
+	for i, group := range c.Groups {
+		names[i] = group.Name
+	}
+	return names
+}
+
+// validate checks a group for mistakes that would otherwise only surface when it is used.
+func (g Group) validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("invalid groups configuration: group without a name")
+	}
+	if len(g.Contexts) == 0 && g.Selector == "" {
+		return fmt.Errorf("invalid group '%s': no contexts or selector", g.Name)
+	}
+	if _, err := labels.Parse(g.Selector); err != nil {
+		return fmt.Errorf("invalid selector for group '%s': %w", g.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package config
+
+import "fmt"
+
+// Hook is a command run around switches, given as the executable followed by its arguments.
+// It is run directly rather than through a shell.
+type Hook []string
+
+// validateHooks checks that every hook names an executable.
+func validateHooks(hooks []Hook) error {
+	for _, hook := range hooks {
+		if len(hook) == 0 || hook[0] == "" {
+			return fmt.Errorf("hook without a command")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// credentialExpiry returns when the client certificate or bearer token of a user expires,
+// whichever comes first, or the zero time if neither has a known expiry. Relative file paths are
+// resolved against dir, the directory of the kubeconfig file. Tokens only have a known expiry if
+// they are JWTs with an exp claim; credentials obtained through exec plugins are not covered.
+func credentialExpiry(authInfo *clientcmdapi.AuthInfo, dir string) time.Time {
+	if authInfo == nil {
+		return time.Time{}
+	}
+
+	certData := authInfo.ClientCertificateData
+	if len(certData) == 0 && authInfo.ClientCertificate != "" {
+		certData, _ = os.ReadFile(resolvePath(authInfo.ClientCertificate, dir))
+	}
+	token := authInfo.Token
+	if token == "" && authInfo.TokenFile != "" {
+		if data, err := os.ReadFile(resolvePath(authInfo.TokenFile, dir)); err == nil {
+			token = strings.TrimSpace(string(data))
+		}
+	}
+
+	expiry := certificateExpiry(certData)
+	if tokenExpiry := tokenExpiry(token); !tokenExpiry.IsZero() && (expiry.IsZero() || tokenExpiry.Before(expiry)) {
+		expiry = tokenExpiry
+	}
+	return expiry
+}
+
+// certificateExpiry returns when the first certificate in PEM data expires, or the zero time if
+// there is none.
+func certificateExpiry(data []byte) time.Time {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}
+	}
+	return cert.NotAfter
+}
+
+// tokenExpiry returns the exp claim of a JWT, or the zero time if the token is not a JWT or has no
+// expiry. The signature is not verified, as only the cluster can do that.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(claims.Exp), 0)
+}
+
+// resolvePath resolves a path relative to dir, as kubectl does for paths in kubeconfig files.
+func resolvePath(path, dir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,127 @@
+package manager
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// testJWT builds an unsigned JWT with the given claims.
+func testJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"RS256"}`)) + "." + encode([]byte(claims)) + ".signature"
+}
+
+// testCertificate returns a PEM-encoded self-signed certificate expiring at notAfter.
+func testCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  time.Time
+	}{
+		{"jwt", testJWT(`{"sub":"admin","exp":1767225600}`), time.Unix(1767225600, 0)},
+		{"fractional exp", testJWT(`{"exp":1767225600.5}`), time.Unix(1767225600, 0)},
+		{"padded payload", "header." + base64.URLEncoding.EncodeToString([]byte(`{"exp":1767225600 }`)) + ".signature", time.Unix(1767225600, 0)},
+		{"no exp", testJWT(`{"sub":"admin"}`), time.Time{}},
+		{"zero exp", testJWT(`{"exp":0}`), time.Time{}},
+		{"invalid payload", "header.!!!.signature", time.Time{}},
+		{"payload not json", "header." + base64.RawURLEncoding.EncodeToString([]byte("exp")) + ".signature", time.Time{}},
+		{"opaque token", "abcdef0123456789", time.Time{}},
+		{"empty", "", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenExpiry(tt.token); !got.Equal(tt.want) {
+				t.Errorf("tokenExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertificateExpiry(t *testing.T) {
+	notAfter := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		data []byte
+		want time.Time
+	}{
+		{"certificate", testCertificate(t, notAfter), notAfter},
+		{"not pem", []byte("not a certificate"), time.Time{}},
+		{"invalid certificate", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}), time.Time{}},
+		{"empty", nil, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certificateExpiry(tt.data); !got.Equal(tt.want) {
+				t.Errorf("certificateExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialExpiry(t *testing.T) {
+	dir := t.TempDir()
+	certExpiry := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	cert := testCertificate(t, certExpiry)
+	if err := os.WriteFile(filepath.Join(dir, "admin.crt"), cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	earlier, later := time.Unix(1767225600, 0), time.Unix(1893456000, 0)
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte(testJWT(`{"exp":1767225600}`)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		authInfo *clientcmdapi.AuthInfo
+		want     time.Time
+	}{
+		{"no user", nil, time.Time{}},
+		{"embedded certificate", &clientcmdapi.AuthInfo{ClientCertificateData: cert}, certExpiry},
+		{"relative certificate file", &clientcmdapi.AuthInfo{ClientCertificate: "admin.crt"}, certExpiry},
+		{"missing certificate file", &clientcmdapi.AuthInfo{ClientCertificate: "gone.crt"}, time.Time{}},
+		{"token file", &clientcmdapi.AuthInfo{TokenFile: filepath.Join(dir, "token")}, earlier},
+		{"token expires first", &clientcmdapi.AuthInfo{ClientCertificateData: cert, Token: testJWT(`{"exp":1767225600}`)}, earlier},
+		{"certificate expires first", &clientcmdapi.AuthInfo{ClientCertificateData: cert, Token: testJWT(`{"exp":1893456000}`)}, certExpiry},
+		{"token only", &clientcmdapi.AuthInfo{Token: testJWT(`{"exp":1893456000}`)}, later},
+		{"exec plugin", &clientcmdapi.AuthInfo{Exec: &clientcmdapi.ExecConfig{Command: "aws"}}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := credentialExpiry(tt.authInfo, dir); !got.Equal(tt.want) {
+				t.Errorf("credentialExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
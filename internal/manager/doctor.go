@@ -0,0 +1,303 @@
+package manager
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// CheckStatus is the outcome of a single diagnostic check.
+type CheckStatus string
+
+const (
+	CheckOK   CheckStatus = "ok"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	// CheckSkip means the check did not apply, or could not run because an earlier one failed
+	CheckSkip CheckStatus = "skip"
+)
+
+// Names of the diagnostic checks, in the order they are run.
+const (
+	CheckFile   = "file"
+	CheckPlugin = "plugin"
+	CheckTLS    = "tls"
+	CheckAPI    = "api"
+	CheckAuth   = "auth"
+)
+
+// caExpiryWarning is how long before the expiry of a CA certificate the TLS check warns about it.
+const caExpiryWarning = 30 * 24 * time.Hour
+
+// Check holds the outcome of a diagnostic check, with a message explaining it.
+type Check struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// Diagnosis holds the outcome of the diagnostic checks of a single context.
+type Diagnosis struct {
+	Context string
+	File    string
+	Server  string
+	// Latency is the duration of a request to the API server, if it could be reached
+	Latency time.Duration
+	Checks  []Check
+}
+
+// Check returns the outcome of the named check.
+func (d Diagnosis) Check(name string) Check {
+	for _, check := range d.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return Check{Name: name, Status: CheckSkip}
+}
+
+// Failed reports whether any check of the context failed.
+func (d Diagnosis) Failed() bool {
+	for _, check := range d.Checks {
+		if check.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnose checks the given contexts concurrently: the permissions of their kubeconfig files, the
+// presence of their credential plugins, their TLS settings, whether their API servers can be
+// reached and whether their credentials are accepted. With fix, kubeconfig files accessible by
+// group or others are made private. The results are in the same order as contextNames.
+func (m *Manager) Diagnose(ctx context.Context, contextNames []string, fix bool) []Diagnosis {
+	results := make([]Diagnosis, len(contextNames))
+
+	// Files are checked (and fixed) once, as several contexts may share one
+	fileChecks := make(map[string]Check)
+	for i, contextName := range contextNames {
+		path := m.contextMap[contextName]
+		results[i] = Diagnosis{Context: contextName, File: path, Server: m.contextInfo[contextName].Server}
+		if _, checked := fileChecks[path]; !checked {
+			fileChecks[path] = checkFilePermissions(path, fix)
+		}
+		results[i].Checks = append(results[i].Checks, fileChecks[path])
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, contextConcurrency)
+	for i, contextName := range contextNames {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			m.diagnoseCluster(ctx, contextName, &results[i])
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+// diagnoseCluster runs the checks of a context that depend on its cluster and credentials.
+func (m *Manager) diagnoseCluster(ctx context.Context, contextName string, d *Diagnosis) {
+	kubeconfig, err := m.loadContextKubeconfig(contextName)
+	if err != nil {
+		d.Checks = append(d.Checks, Check{Name: CheckAPI, Status: CheckFail, Message: err.Error()})
+		return
+	}
+	var authInfo *clientcmdapi.AuthInfo
+	if kubeCtx, exists := kubeconfig.Contexts[contextName]; exists {
+		authInfo = kubeconfig.AuthInfos[kubeCtx.AuthInfo]
+	}
+	plugin := checkPlugin(authInfo)
+	d.Checks = append(d.Checks, plugin)
+
+	config, err := m.restConfig(contextName)
+	if err != nil {
+		d.Checks = append(d.Checks, Check{Name: CheckAPI, Status: CheckFail, Message: err.Error()})
+		return
+	}
+	config.Timeout = m.requestTimeout
+	tlsCheck := checkTLSConfig(config)
+
+	// Reaching the API server does not depend on the credentials, so it is probed anonymously
+	latency, err := m.probeServer(ctx, config)
+	var api Check
+	switch {
+	case err == nil:
+		d.Latency = latency
+		api = Check{Name: CheckAPI, Status: CheckOK, Message: fmt.Sprintf("reachable in %s", latency.Round(time.Millisecond))}
+	case isCertificateError(err):
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		tlsCheck = Check{Name: CheckTLS, Status: CheckFail, Message: err.Error()}
+		api = Check{Name: CheckAPI, Status: CheckSkip, Message: "TLS verification failed"}
+	default:
+		api = Check{Name: CheckAPI, Status: CheckFail, Message: clusterError(ctx, config.Host, "request failed", err).Error()}
+	}
+	d.Checks = append(d.Checks, tlsCheck, api)
+
+	var auth Check
+	switch {
+	case api.Status != CheckOK:
+		auth = Check{Name: CheckAuth, Status: CheckSkip, Message: "API server not reachable"}
+	case plugin.Status == CheckFail:
+		auth = Check{Name: CheckAuth, Status: CheckSkip, Message: "credential plugin not available"}
+	default:
+		auth = m.checkAuth(ctx, contextName)
+	}
+	d.Checks = append(d.Checks, auth)
+}
+
+// probeServer requests the version of the API server without credentials and returns how long
+// it took. Error responses count as success, as they show that the server is reachable.
+func (m *Manager) probeServer(ctx context.Context, config *rest.Config) (time.Duration, error) {
+	client, err := discovery.NewDiscoveryClientForConfig(rest.AnonymousClientConfig(config))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	ctx, cancel := m.withRequestTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	err = client.RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+	latency := time.Since(start)
+	var status apierrors.APIStatus
+	if err != nil && !errors.As(err, &status) {
+		return 0, err
+	}
+	return latency, nil
+}
+
+// checkAuth checks that the cluster accepts the credentials of the context.
+func (m *Manager) checkAuth(ctx context.Context, contextName string) Check {
+	identity, err := m.WhoAmI(ctx, contextName)
+	switch {
+	case err == nil:
+		return Check{Name: CheckAuth, Status: CheckOK, Message: fmt.Sprintf("authenticated as '%s'", identity.Username)}
+	case errors.Is(err, ErrReviewUnsupported):
+		return Check{Name: CheckAuth, Status: CheckWarn, Message: "cannot verify credentials: " + err.Error()}
+	default:
+		return Check{Name: CheckAuth, Status: CheckFail, Message: err.Error()}
+	}
+}
+
+// checkFilePermissions warns about kubeconfig files that are accessible by group or others, which
+// it makes private with fix. File permissions are not checked on Windows.
+func checkFilePermissions(path string, fix bool) Check {
+	if runtime.GOOS == "windows" {
+		return Check{Name: CheckFile, Status: CheckSkip, Message: "file permissions are not checked on Windows"}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Check{Name: CheckFile, Status: CheckFail, Message: fmt.Sprintf("failed to stat kubeconfig file: %v", err)}
+	}
+	perm := info.Mode().Perm()
+	if perm&0o077 == 0 {
+		return Check{Name: CheckFile, Status: CheckOK, Message: fmt.Sprintf("permissions %04o", perm)}
+	}
+
+	if fix {
+		if err := os.Chmod(path, perm&^0o077); err != nil {
+			return Check{Name: CheckFile, Status: CheckFail, Message: fmt.Sprintf("failed to fix permissions %04o: %v", perm, err)}
+		}
+		return Check{Name: CheckFile, Status: CheckOK, Message: fmt.Sprintf("fixed permissions %04o to %04o", perm, perm&^0o077)}
+	}
+	return Check{Name: CheckFile, Status: CheckWarn, Message: fmt.Sprintf("permissions %04o allow access by group or others (fix with --fix)", perm)}
+}
+
+// checkPlugin checks that the credential plugin of a user, if any, can be found on the PATH.
+func checkPlugin(authInfo *clientcmdapi.AuthInfo) Check {
+	switch {
+	case authInfo == nil:
+		return Check{Name: CheckPlugin, Status: CheckSkip, Message: "no user"}
+	case authInfo.AuthProvider != nil:
+		return Check{Name: CheckPlugin, Status: CheckFail, Message: fmt.Sprintf("auth provider '%s' is no longer supported by kubectl, use its exec plugin instead", authInfo.AuthProvider.Name)}
+	case authInfo.Exec == nil:
+		return Check{Name: CheckPlugin, Status: CheckSkip, Message: "no credential plugin"}
+	}
+
+	path, err := exec.LookPath(authInfo.Exec.Command)
+	if err != nil {
+		message := fmt.Sprintf("credential plugin '%s' not found", authInfo.Exec.Command)
+		if authInfo.Exec.InstallHint != "" {
+			message += ": " + authInfo.Exec.InstallHint
+		}
+		return Check{Name: CheckPlugin, Status: CheckFail, Message: message}
+	}
+	return Check{Name: CheckPlugin, Status: CheckOK, Message: "found " + path}
+}
+
+// checkTLSConfig checks the TLS settings of a cluster: that TLS is used and verified, and that
+// the configured CA certificates can be parsed and have not expired.
+func checkTLSConfig(config *rest.Config) Check {
+	if u, err := url.Parse(config.Host); err == nil && u.Scheme == "http" {
+		return Check{Name: CheckTLS, Status: CheckWarn, Message: "the API server is reached over plain HTTP"}
+	}
+	if config.Insecure {
+		return Check{Name: CheckTLS, Status: CheckWarn, Message: "certificate verification is disabled (insecure-skip-tls-verify)"}
+	}
+
+	caData := config.CAData
+	if len(caData) == 0 && config.CAFile != "" {
+		var err error
+		if caData, err = os.ReadFile(config.CAFile); err != nil {
+			return Check{Name: CheckTLS, Status: CheckFail, Message: fmt.Sprintf("failed to read CA file: %v", err)}
+		}
+	}
+	if len(caData) == 0 {
+		return Check{Name: CheckTLS, Status: CheckOK, Message: "verified against the system CAs"}
+	}
+
+	var certs []*x509.Certificate
+	for block, remaining := pem.Decode(caData); block != nil; block, remaining = pem.Decode(remaining) {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return Check{Name: CheckTLS, Status: CheckFail, Message: fmt.Sprintf("invalid CA certificate: %v", err)}
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return Check{Name: CheckTLS, Status: CheckFail, Message: "no CA certificates found in the CA data"}
+	}
+
+	now := time.Now()
+	for _, cert := range certs {
+		if now.After(cert.NotAfter) {
+			return Check{Name: CheckTLS, Status: CheckFail, Message: fmt.Sprintf("CA certificate '%s' expired on %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))}
+		}
+		if now.Add(caExpiryWarning).After(cert.NotAfter) {
+			return Check{Name: CheckTLS, Status: CheckWarn, Message: fmt.Sprintf("CA certificate '%s' expires on %s", cert.Subject.CommonName, cert.NotAfter.Format(time.DateOnly))}
+		}
+	}
+	return Check{Name: CheckTLS, Status: CheckOK, Message: "verified against the configured CA"}
+}
+
+// isCertificateError reports whether err is caused by a failure to verify the server certificate.
+func isCertificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mirceanton/kubectl-switch/v2/internal/yamledit"
+	"go.yaml.in/yaml/v3"
+)
+
+// setNamespaceInFile sets the namespace of a context in a kubeconfig file by editing only that
+// field, keeping comments, the order of fields and unknown fields. An empty namespace removes the
+// field.
+func setNamespaceInFile(path, contextName, namespace string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig from %s: %w", path, err)
+	}
+
+	doc, root, err := yamledit.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse kubeconfig from %s: %w", path, err)
+	}
+
+	context := findContextNode(root, contextName)
+	if context == nil {
+		return fmt.Errorf("context '%s' not found in %s", contextName, path)
+	}
+	yamledit.SetString(context, "namespace", namespace)
+
+	out, err := yamledit.Encode(doc, data)
+	if err != nil {
+		return fmt.Errorf("failed to encode kubeconfig: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0o600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig to %s: %w", path, err)
+	}
+	return nil
+}
+
+// findContextNode returns the context mapping (the value of its context field) of the named entry
+// in the contexts list of a kubeconfig, or nil if there is none.
+func findContextNode(root *yaml.Node, contextName string) *yaml.Node {
+	contexts := yamledit.Lookup(root, "contexts")
+	if contexts == nil || contexts.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, entry := range contexts.Content {
+		if entry.Kind != yaml.MappingNode {
+			continue
+		}
+		if name := yamledit.Lookup(entry, "name"); name == nil || name.Value != contextName {
+			continue
+		}
+		return yamledit.Value(entry, "context", yaml.MappingNode)
+	}
+	return nil
+}
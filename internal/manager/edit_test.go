@@ -0,0 +1,114 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// editFile is a kubeconfig file as written by kubectl, with sequences not indented.
+const editFile = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+users:
+- name: prod
+  user:
+    token: secret
+contexts:
+# production, handle with care
+- name: prod
+  context:
+    cluster: prod
+    namespace: default
+    user: prod
+  extensions:
+  - name: kubectl-switch
+    extension:
+      labels:
+        env: prod
+current-context: prod
+`
+
+// indentedEditFile is editFile with sequences indented, as other tools write them.
+const indentedEditFile = `apiVersion: v1
+kind: Config
+contexts:
+  # production, handle with care
+  - name: prod
+    context:
+      cluster: prod
+      namespace: default
+      user: prod
+current-context: prod
+`
+
+func TestSetNamespaceInFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		context   string
+		namespace string
+		// Line replacing "namespace: default" in the initial file, or "" if it is removed
+		wantLine string
+		wantErr  bool
+	}{
+		{name: "plain name", initial: editFile, context: "prod", namespace: "payments", wantLine: "    namespace: payments"},
+		{name: "number", initial: editFile, context: "prod", namespace: "123", wantLine: `    namespace: "123"`},
+		{name: "leading zero", initial: editFile, context: "prod", namespace: "0123", wantLine: `    namespace: "0123"`},
+		{name: "true", initial: editFile, context: "prod", namespace: "true", wantLine: `    namespace: "true"`},
+		{name: "no", initial: editFile, context: "prod", namespace: "no", wantLine: `    namespace: "no"`},
+		{name: "on", initial: editFile, context: "prod", namespace: "on", wantLine: `    namespace: "on"`},
+		{name: "y", initial: editFile, context: "prod", namespace: "y", wantLine: `    namespace: "y"`},
+		{name: "remove", initial: editFile, context: "prod", namespace: ""},
+		{name: "indented sequences", initial: indentedEditFile, context: "prod", namespace: "payments", wantLine: "      namespace: payments"},
+		{name: "unknown context", initial: editFile, context: "dev", namespace: "payments", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.initial), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := setNamespaceInFile(path, tt.context, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setNamespaceInFile() error = %v, want error %v", err, tt.wantErr)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := tt.initial
+			if !tt.wantErr {
+				lines := strings.SplitAfter(tt.initial, "\n")
+				for i, line := range lines {
+					if strings.TrimSpace(line) == "namespace: default" {
+						lines[i] = ""
+						if tt.wantLine != "" {
+							lines[i] = tt.wantLine + "\n"
+						}
+					}
+				}
+				want = strings.Join(lines, "")
+			}
+			if string(got) != want {
+				t.Errorf("kubeconfig =\n%s\nwant\n%s", got, want)
+			}
+
+			config, err := clientcmd.LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile() failed: %v", err)
+			}
+			if context := config.Contexts[tt.context]; context != nil && context.Namespace != tt.namespace {
+				t.Errorf("namespace = %q, want %q", context.Namespace, tt.namespace)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package manager
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Export builds a single kubeconfig holding the given contexts along with their clusters and
+// users, with relative file paths resolved so it can be used from anywhere. Clusters and users
+// that are named the same in different files but are defined differently are renamed after their
+// context. The current context is kept if exported, otherwise the first context becomes current.
+// With flatten, referenced certificate, key and token files are embedded. LoadContexts must have
+// been called first.
+func (m *Manager) Export(contextNames []string, flatten bool) (*clientcmdapi.Config, error) {
+	exported := clientcmdapi.NewConfig()
+	loaded := make(map[string]*clientcmdapi.Config)
+
+	for _, contextName := range contextNames {
+		path, exists := m.contextMap[contextName]
+		if !exists {
+			return nil, newNotFoundError("context", contextName, m.contextNames)
+		}
+
+		kubeconfig, cached := loaded[path]
+		if !cached {
+			var err error
+			kubeconfig, err = clientcmd.LoadFromFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load kubeconfig from %s: %w", path, err)
+			}
+			if err := clientcmd.ResolveLocalPaths(kubeconfig); err != nil {
+				return nil, fmt.Errorf("failed to resolve paths in %s: %w", path, err)
+			}
+			loaded[path] = kubeconfig
+		}
+
+		ctx := kubeconfig.Contexts[contextName].DeepCopy()
+		if cluster, exists := kubeconfig.Clusters[ctx.Cluster]; exists {
+			name, err := exportName(exported.Clusters, ctx.Cluster, contextName, cluster, sameCluster)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export cluster of context '%s': %w", contextName, err)
+			}
+			exported.Clusters[name] = cluster.DeepCopy()
+			ctx.Cluster = name
+		}
+		if authInfo, exists := kubeconfig.AuthInfos[ctx.AuthInfo]; exists {
+			name, err := exportName(exported.AuthInfos, ctx.AuthInfo, contextName, authInfo, sameAuthInfo)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export user of context '%s': %w", contextName, err)
+			}
+			exported.AuthInfos[name] = authInfo.DeepCopy()
+			ctx.AuthInfo = name
+		}
+		exported.Contexts[contextName] = ctx
+	}
+
+	if currentContext := m.GetCurrentContext(); exported.Contexts[currentContext] != nil {
+		exported.CurrentContext = currentContext
+	} else if len(contextNames) > 0 {
+		exported.CurrentContext = contextNames[0]
+	}
+
+	if flatten {
+		if err := clientcmdapi.FlattenConfig(exported); err != nil {
+			return nil, fmt.Errorf("failed to embed referenced files: %w", err)
+		}
+	}
+	return exported, nil
+}
+
+// exportName returns the name to export a cluster or user under: its own, unless that is taken by
+// a different definition, in which case it is named after its context.
+func exportName[T any](taken map[string]*T, name, contextName string, value *T, same func(a, b *T) bool) (string, error) {
+	for _, candidate := range []string{name, contextName} {
+		if existing, exists := taken[candidate]; !exists || same(existing, value) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("name '%s' is already used by a different definition", name)
+}
+
+// sameCluster reports whether two clusters are defined the same, regardless of the file they came from.
+func sameCluster(a, b *clientcmdapi.Cluster) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	a.LocationOfOrigin, b.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// sameAuthInfo reports whether two users are defined the same, regardless of the file they came from.
+func sameAuthInfo(a, b *clientcmdapi.AuthInfo) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	a.LocationOfOrigin, b.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(a, b)
+}
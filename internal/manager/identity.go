@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"context"
+	"errors"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	// ErrUnauthorized is returned when the cluster rejects the credentials of a context.
+	ErrUnauthorized = errors.New("the cluster rejected the credentials")
+	// ErrReviewUnsupported is returned when the cluster does not serve the SelfSubjectReview API.
+	ErrReviewUnsupported = errors.New("the cluster does not support the SelfSubjectReview API (Kubernetes 1.27 or later)")
+)
+
+// Identity describes the user that the credentials of a context authenticate as.
+type Identity struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+}
+
+// WhoAmI asks the cluster of the given context (or of the current one) which user its credentials
+// authenticate as, using the SelfSubjectReview API. Clusters that only serve the beta version of
+// the API (Kubernetes 1.27) are supported as well.
+func (m *Manager) WhoAmI(ctx context.Context, contextName string) (Identity, error) {
+	clientset, config, err := m.newClientset(contextName)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	ctx, cancel := m.withRequestTimeout(ctx)
+	defer cancel()
+
+	var user authenticationv1.UserInfo
+	review, err := clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		user = review.Status.UserInfo
+	} else if apierrors.IsNotFound(err) {
+		var betaReview *authenticationv1beta1.SelfSubjectReview
+		betaReview, err = clientset.AuthenticationV1beta1().SelfSubjectReviews().Create(ctx, &authenticationv1beta1.SelfSubjectReview{}, metav1.CreateOptions{})
+		if err == nil {
+			user = betaReview.Status.UserInfo
+		}
+	}
+	if err != nil {
+		if apierrors.IsUnauthorized(err) {
+			return Identity{}, ErrUnauthorized
+		}
+		if apierrors.IsNotFound(err) {
+			return Identity{}, ErrReviewUnsupported
+		}
+		return Identity{}, clusterError(ctx, config.Host, "failed to review credentials", err)
+	}
+
+	identity := Identity{
+		Username: user.Username,
+		UID:      user.UID,
+		Groups:   user.Groups,
+	}
+	if len(user.Extra) > 0 {
+		identity.Extra = make(map[string][]string, len(user.Extra))
+		for key, values := range user.Extra {
+			identity.Extra[key] = values
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,163 @@
+package manager
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// LintSeverity tells how serious a problem found by Lint is.
+type LintSeverity string
+
+const (
+	// LintError is a problem that makes a file or context unusable
+	LintError LintSeverity = "error"
+	// LintWarning is a problem that does not prevent switching, but likely needs attention
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue is a problem found in a kubeconfig file, and the context it affects, if any.
+type LintIssue struct {
+	Severity LintSeverity
+	File     string
+	Context  string
+	Message  string
+}
+
+// Lint checks the kubeconfig files in the config directory for problems that LoadContexts skips
+// over or that break switching to their contexts: unparsable files, contexts referencing missing
+// clusters or users, names defined in several files, missing certificate files, disabled TLS
+// verification and missing or unknown current contexts. Issues are reported in file order.
+func (m *Manager) Lint() ([]LintIssue, error) {
+	files, err := os.ReadDir(m.kubeconfigDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+
+	var issues []LintIssue
+	// The files each name was first defined in, per kind
+	defined := map[string]map[string]string{"context": {}, "cluster": {}, "user": {}}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(m.kubeconfigDir, file.Name())
+		add := func(severity LintSeverity, contextName, format string, args ...any) {
+			issues = append(issues, LintIssue{Severity: severity, File: path, Context: contextName, Message: fmt.Sprintf(format, args...)})
+		}
+
+		kubeconfig, err := clientcmd.LoadFromFile(path)
+		if err != nil {
+			add(LintError, "", "failed to parse kubeconfig file: %v", err)
+			continue
+		}
+		if err := clientcmd.ResolveLocalPaths(kubeconfig); err != nil {
+			add(LintError, "", "failed to resolve paths: %v", err)
+			continue
+		}
+
+		// Only the first definition of a context is used; clusters and users are only at risk of
+		// clashing once files are merged, e.g. through KUBECONFIG
+		contextNames := contextsInFileOrder(path, kubeconfig)
+		names := map[string][]string{
+			"context": contextNames,
+			"cluster": slices.Sorted(maps.Keys(kubeconfig.Clusters)),
+			"user":    slices.Sorted(maps.Keys(kubeconfig.AuthInfos)),
+		}
+		for _, kind := range []string{"context", "cluster", "user"} {
+			for _, name := range names[kind] {
+				firstPath, exists := defined[kind][name]
+				if !exists {
+					defined[kind][name] = path
+					continue
+				}
+				if kind == "context" {
+					add(LintError, name, "context is also defined in %s, which takes precedence", filepath.Base(firstPath))
+				} else {
+					add(LintWarning, "", "%s '%s' is also defined in %s", kind, name, filepath.Base(firstPath))
+				}
+			}
+		}
+
+		switch {
+		case len(contextNames) == 0:
+			add(LintWarning, "", "file defines no contexts")
+		case kubeconfig.CurrentContext == "":
+			add(LintWarning, "", "current-context is empty")
+		case kubeconfig.Contexts[kubeconfig.CurrentContext] == nil:
+			add(LintError, "", "current-context '%s' is not defined in the file", kubeconfig.CurrentContext)
+		}
+
+		for _, contextName := range contextNames {
+			for _, issue := range lintContext(kubeconfig, contextName) {
+				issue.File = path
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// lintContext checks that a context's cluster and user are defined and usable.
+func lintContext(kubeconfig *clientcmdapi.Config, contextName string) []LintIssue {
+	var issues []LintIssue
+	add := func(severity LintSeverity, format string, args ...any) {
+		issues = append(issues, LintIssue{Severity: severity, Context: contextName, Message: fmt.Sprintf(format, args...)})
+	}
+	ctx := kubeconfig.Contexts[contextName]
+
+	if cluster, exists := kubeconfig.Clusters[ctx.Cluster]; !exists {
+		add(LintError, "cluster '%s' is not defined", ctx.Cluster)
+	} else {
+		if cluster.Server == "" {
+			add(LintError, "cluster '%s' has no server", ctx.Cluster)
+		}
+		if cluster.InsecureSkipTLSVerify {
+			add(LintWarning, "cluster '%s' disables certificate verification (insecure-skip-tls-verify)", ctx.Cluster)
+		}
+		if missing := missingFile(cluster.CertificateAuthority); missing != "" {
+			add(LintError, "certificate-authority of cluster '%s': %s", ctx.Cluster, missing)
+		}
+	}
+
+	if ctx.AuthInfo == "" {
+		return issues
+	}
+	authInfo, exists := kubeconfig.AuthInfos[ctx.AuthInfo]
+	if !exists {
+		add(LintError, "user '%s' is not defined", ctx.AuthInfo)
+		return issues
+	}
+	for _, file := range []struct{ field, path string }{
+		{"client-certificate", authInfo.ClientCertificate},
+		{"client-key", authInfo.ClientKey},
+		{"tokenFile", authInfo.TokenFile},
+	} {
+		if missing := missingFile(file.path); missing != "" {
+			add(LintError, "%s of user '%s': %s", file.field, ctx.AuthInfo, missing)
+		}
+	}
+	return issues
+}
+
+// missingFile describes why the file at path cannot be used, or returns an empty string if it can
+// or no path is set.
+func missingFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Sprintf("%s does not exist", path)
+		}
+		return fmt.Sprintf("%s cannot be read: %v", path, err)
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// lintFile is a valid kubeconfig file defining the context, cluster and user named prod.
+const lintFile = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+users:
+- name: prod
+  user:
+    token: secret
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: prod
+current-context: prod
+`
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		// Issues with the base name of their file; messages only need to match as a prefix, and
+		// {dir} in them stands for the kubeconfig directory
+		want []LintIssue
+	}{
+		{
+			name:  "valid",
+			files: map[string]string{"prod.yaml": lintFile},
+		},
+		{
+			name:  "unparsable file",
+			files: map[string]string{"broken.yaml": "clusters: [", "prod.yaml": lintFile},
+			want:  []LintIssue{{LintError, "broken.yaml", "", "failed to parse kubeconfig file"}},
+		},
+		{
+			name: "undefined cluster and user",
+			files: map[string]string{"prod.yaml": strings.NewReplacer(
+				"cluster: prod", "cluster: gone",
+				"user: prod", "user: missing",
+			).Replace(lintFile)},
+			want: []LintIssue{
+				{LintError, "prod.yaml", "prod", "cluster 'gone' is not defined"},
+				{LintError, "prod.yaml", "prod", "user 'missing' is not defined"},
+			},
+		},
+		{
+			name:  "duplicate names",
+			files: map[string]string{"a.yaml": lintFile, "b.yaml": lintFile},
+			want: []LintIssue{
+				{LintError, "b.yaml", "prod", "context is also defined in a.yaml, which takes precedence"},
+				{LintWarning, "b.yaml", "", "cluster 'prod' is also defined in a.yaml"},
+				{LintWarning, "b.yaml", "", "user 'prod' is also defined in a.yaml"},
+			},
+		},
+		{
+			name: "missing files and insecure cluster",
+			files: map[string]string{"prod.yaml": strings.NewReplacer(
+				"server: https://prod.example.com", "server: https://prod.example.com\n    insecure-skip-tls-verify: true",
+				"token: secret", "client-certificate: certs/prod.crt\n    client-key: certs/prod.key",
+			).Replace(lintFile)},
+			want: []LintIssue{
+				{LintWarning, "prod.yaml", "prod", "cluster 'prod' disables certificate verification (insecure-skip-tls-verify)"},
+				{LintError, "prod.yaml", "prod", "client-certificate of user 'prod': {dir}/certs/prod.crt does not exist"},
+				{LintError, "prod.yaml", "prod", "client-key of user 'prod': {dir}/certs/prod.key does not exist"},
+			},
+		},
+		{
+			name:  "empty current context",
+			files: map[string]string{"prod.yaml": strings.Replace(lintFile, "current-context: prod", "current-context: \"\"", 1)},
+			want:  []LintIssue{{LintWarning, "prod.yaml", "", "current-context is empty"}},
+		},
+		{
+			name:  "unknown current context",
+			files: map[string]string{"prod.yaml": strings.Replace(lintFile, "current-context: prod", "current-context: dev", 1)},
+			want:  []LintIssue{{LintError, "prod.yaml", "", "current-context 'dev' is not defined in the file"}},
+		},
+		{
+			name:  "no contexts",
+			files: map[string]string{"empty.yaml": "apiVersion: v1\nkind: Config\n"},
+			want:  []LintIssue{{LintWarning, "empty.yaml", "", "file defines no contexts"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m, err := NewManager(filepath.Join(t.TempDir(), "config"), dir, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			issues, err := m.Lint()
+			if err != nil {
+				t.Fatalf("Lint() failed: %v", err)
+			}
+
+			var got []LintIssue
+			for _, issue := range issues {
+				issue.File = filepath.Base(issue.File)
+				got = append(got, issue)
+			}
+			var want []LintIssue
+			for _, issue := range tt.want {
+				issue.Message = strings.ReplaceAll(issue.Message, "{dir}", dir)
+				want = append(want, issue)
+			}
+			matches := func(got, want LintIssue) bool {
+				return got.Severity == want.Severity && got.File == want.File && got.Context == want.Context &&
+					strings.HasPrefix(got.Message, want.Message)
+			}
+			if !slices.EqualFunc(got, want, matches) {
+				t.Errorf("Lint() =\n%v\nwant\n%v", got, want)
+			}
+		})
+	}
+}
@@ -1,15 +1,21 @@
 package manager
 
 import (
-	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/mirceanton/kubectl-switch/v2/internal/config"
 	log "github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
 )
 
 // Manager handles kubeconfig file operations and Kubernetes context switching.
@@ -17,18 +23,23 @@ type Manager struct {
 	kubeconfigPath string
 	backupPath     string
 	kubeconfigDir  string
+	requestTimeout time.Duration
 	contextMap     map[string]string
+	contextInfo    map[string]ContextInfo
 	contextNames   []string
 	namespaceNames []string
 }
 
 // NewManager creates a new kubeconfig Manager instance.
-func NewManager(kubeconfigPath, kubeconfigDir string) (*Manager, error) {
+// A zero requestTimeout means cluster requests are only bounded by the caller's context.
+func NewManager(kubeconfigPath, kubeconfigDir string, requestTimeout time.Duration) (*Manager, error) {
 	m := &Manager{
 		kubeconfigPath: kubeconfigPath,
 		kubeconfigDir:  kubeconfigDir,
+		requestTimeout: requestTimeout,
 		backupPath:     kubeconfigPath + ".previous",
 		contextMap:     make(map[string]string),
+		contextInfo:    make(map[string]ContextInfo),
 		contextNames:   []string{},
 		namespaceNames: []string{},
 	}
@@ -36,6 +47,41 @@ func NewManager(kubeconfigPath, kubeconfigDir string) (*Manager, error) {
 	return m, nil
 }
 
+// ContextInfo describes a context as defined in its kubeconfig file.
+type ContextInfo struct {
+	Name      string
+	Cluster   string
+	User      string
+	Namespace string
+	// Server is the API server URL of the context's cluster
+	Server string
+	// File is the path of the kubeconfig file the context was loaded from
+	File string
+	// CredentialExpiry is when the client certificate or token of the context's user expires, or
+	// the zero time if unknown
+	CredentialExpiry time.Time
+
+	// Settings from the kubectl-switch extension of the context
+	Environment string
+	Color       string
+	Protected   bool
+	Labels      map[string]string
+	Env         map[string]string
+}
+
+// extensionName is the name under which kubectl-switch settings can be stored in the extensions
+// field of a kubeconfig context.
+const extensionName = "kubectl-switch"
+
+// contextExtension holds the kubectl-switch settings stored in a kubeconfig context.
+type contextExtension struct {
+	Environment string            `json:"environment"`
+	Color       string            `json:"color"`
+	Protected   bool              `json:"protected"`
+	Labels      map[string]string `json:"labels"`
+	Env         map[string]string `json:"env"`
+}
+
 // GetAllContexts returns the available context names.
 func (m *Manager) GetAllContexts() []string {
 	return m.contextNames
@@ -46,6 +92,11 @@ func (m *Manager) GetAllNamespaces() []string {
 	return m.namespaceNames
 }
 
+// GetContextInfo returns the definition of the given context. LoadContexts must have been called first.
+func (m *Manager) GetContextInfo(contextName string) ContextInfo {
+	return m.contextInfo[contextName]
+}
+
 // GetCurrentContext returns the current context name from the kubeconfig.
 func (m *Manager) GetCurrentContext() string {
 	kubeconfig, err := clientcmd.LoadFromFile(m.kubeconfigPath)
@@ -67,12 +118,47 @@ func (m *Manager) GetCurrentNamespace() string {
 	return ""
 }
 
+// GetPreviousContext returns the current context and namespace of the previous kubeconfig that
+// Restore would switch back to, or empty strings if there is none.
+func (m *Manager) GetPreviousContext() (string, string) {
+	kubeconfig, err := clientcmd.LoadFromFile(m.backupPath)
+	if err != nil {
+		return "", ""
+	}
+	if ctx, exists := kubeconfig.Contexts[kubeconfig.CurrentContext]; exists {
+		return kubeconfig.CurrentContext, ctx.Namespace
+	}
+	return kubeconfig.CurrentContext, ""
+}
+
+// GetContextNamespace returns the namespace configured for the given context in its source file.
+func (m *Manager) GetContextNamespace(contextName string) string {
+	contextFilePath, exists := m.contextMap[contextName]
+	if !exists {
+		return ""
+	}
+	kubeconfig, err := clientcmd.LoadFromFile(contextFilePath)
+	if err != nil {
+		return ""
+	}
+	if ctx, exists := kubeconfig.Contexts[contextName]; exists {
+		return ctx.Namespace
+	}
+	return ""
+}
+
 // SwitchToContext switches to the specified Kubernetes context.
 func (m *Manager) SwitchToContext(contextName string) error {
+	return m.SwitchToContextNamespace(contextName, "")
+}
+
+// SwitchToContextNamespace switches to the specified Kubernetes context and, unless namespace is
+// empty, sets its namespace, writing the kubeconfig (and the previous-config backup) only once.
+func (m *Manager) SwitchToContextNamespace(contextName, namespace string) error {
 	// Find the kubeconfig file containing the desired context
 	contextFilePath, exists := m.contextMap[contextName]
 	if !exists {
-		return fmt.Errorf("context '%s' not found", contextName)
+		return newNotFoundError("context", contextName, m.contextNames)
 	}
 
 	// Load the kubeconfig file containing the desired context
@@ -81,8 +167,11 @@ func (m *Manager) SwitchToContext(contextName string) error {
 		return fmt.Errorf("failed to load kubeconfig from %s: %w", contextFilePath, err)
 	}
 
-	// Update the current context in the loaded kubeconfig
+	// Update the current context (and namespace) in the loaded kubeconfig
 	kubeconfig.CurrentContext = contextName
+	if namespace != "" {
+		kubeconfig.Contexts[contextName].Namespace = namespace
+	}
 
 	// Backup current config
 	if err := m.backup(); err != nil {
@@ -106,7 +195,14 @@ func (m *Manager) SwitchToNamespace(namespace string) error {
 	}
 
 	// Update namespace for current context
-	kubeconfig.Contexts[kubeconfig.CurrentContext].Namespace = namespace
+	currentContext, exists := kubeconfig.Contexts[kubeconfig.CurrentContext]
+	if !exists {
+		if kubeconfig.CurrentContext == "" {
+			return fmt.Errorf("no current context set in %s", m.kubeconfigPath)
+		}
+		return fmt.Errorf("current context '%s' not found in %s", kubeconfig.CurrentContext, m.kubeconfigPath)
+	}
+	currentContext.Namespace = namespace
 
 	// Backup current config
 	if err := m.backup(); err != nil {
@@ -121,6 +217,71 @@ func (m *Manager) SwitchToNamespace(namespace string) error {
 	return nil
 }
 
+// SetContextNamespace sets the namespace of the given context in the kubeconfig file it was loaded
+// from, without changing which context is active or anything else in that file. If the context
+// is the active one, its namespace in the active kubeconfig is updated as well.
+func (m *Manager) SetContextNamespace(contextName, namespace string) error {
+	// Find the kubeconfig file containing the desired context
+	contextFilePath, exists := m.contextMap[contextName]
+	if !exists {
+		return newNotFoundError("context", contextName, m.contextNames)
+	}
+
+	// Update only the namespace in the source kubeconfig file, which belongs to the user
+	if err := setNamespaceInFile(contextFilePath, contextName, namespace); err != nil {
+		return err
+	}
+
+	// Keep the active copy in sync
+	if m.GetCurrentContext() == contextName {
+		return m.SwitchToNamespace(namespace)
+	}
+	return nil
+}
+
+// ValidateContext checks that the given context exists, returning a NotFoundError with the
+// closest matches if it does not. LoadContexts must have been called first.
+func (m *Manager) ValidateContext(contextName string) error {
+	if _, exists := m.contextMap[contextName]; !exists {
+		return newNotFoundError("context", contextName, m.contextNames)
+	}
+	return nil
+}
+
+// Revert replaces the active kubeconfig with the given context (and, unless empty, namespace)
+// without backing up the active one, or with an empty kubeconfig if contextName is empty. It is
+// used to leave a context whose credentials should not linger, so the previous-config backup is
+// removed as well if it points at that context.
+func (m *Manager) Revert(leftContext, contextName, namespace string) error {
+	kubeconfig := clientcmdapi.NewConfig()
+	if contextName != "" {
+		contextFilePath, exists := m.contextMap[contextName]
+		if !exists {
+			return newNotFoundError("context", contextName, m.contextNames)
+		}
+		var err error
+		kubeconfig, err = clientcmd.LoadFromFile(contextFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to load kubeconfig from %s: %w", contextFilePath, err)
+		}
+		kubeconfig.CurrentContext = contextName
+		if namespace != "" {
+			kubeconfig.Contexts[contextName].Namespace = namespace
+		}
+	}
+
+	if err := clientcmd.WriteToFile(*kubeconfig, m.kubeconfigPath); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	if backup, err := clientcmd.LoadFromFile(m.backupPath); err == nil && backup.CurrentContext == leftContext {
+		if err := os.Remove(m.backupPath); err != nil {
+			return fmt.Errorf("failed to remove previous kubeconfig: %w", err)
+		}
+	}
+	return nil
+}
+
 // Restore swaps the current kubeconfig with the previous backup.
 func (m *Manager) Restore() error {
 	// Read current kubeconfig
@@ -164,9 +325,98 @@ func (m *Manager) backup() error {
 	return nil
 }
 
+// contextsInFileOrder returns the names of the contexts defined in a kubeconfig file in the order
+// they appear in it, which the parsed kubeconfig does not preserve.
+func contextsInFileOrder(path string, kubeconfig *clientcmdapi.Config) []string {
+	var names []string
+	var ordered clientcmdapiv1.Config
+	if data, err := os.ReadFile(path); err == nil && yaml.Unmarshal(data, &ordered) == nil {
+		for _, ctx := range ordered.Contexts {
+			if _, exists := kubeconfig.Contexts[ctx.Name]; exists && !slices.Contains(names, ctx.Name) {
+				names = append(names, ctx.Name)
+			}
+		}
+	}
+
+	// Fall back to sorted names for anything the raw file did not reveal
+	var rest []string
+	for contextName := range kubeconfig.Contexts {
+		if !slices.Contains(names, contextName) {
+			rest = append(rest, contextName)
+		}
+	}
+	slices.Sort(rest)
+	return append(names, rest...)
+}
+
+// newContextInfo describes a context of a parsed kubeconfig file.
+func newContextInfo(contextName, path string, kubeconfig *clientcmdapi.Config) ContextInfo {
+	ctx := kubeconfig.Contexts[contextName]
+	info := ContextInfo{
+		Name:      contextName,
+		Cluster:   ctx.Cluster,
+		User:      ctx.AuthInfo,
+		Namespace: ctx.Namespace,
+		File:      path,
+	}
+	if cluster, exists := kubeconfig.Clusters[ctx.Cluster]; exists {
+		info.Server = cluster.Server
+	}
+	info.CredentialExpiry = credentialExpiry(kubeconfig.AuthInfos[ctx.AuthInfo], filepath.Dir(path))
+
+	if raw, exists := ctx.Extensions[extensionName].(*runtime.Unknown); exists {
+		var ext contextExtension
+		if err := json.Unmarshal(raw.Raw, &ext); err != nil {
+			log.WithField("context", contextName).Warnf("Failed to parse %s extension: %v", extensionName, err)
+		}
+		info.Environment = ext.Environment
+		info.Color = ext.Color
+		info.Protected = ext.Protected
+		info.Labels = validLabels(contextName, ext.Labels)
+		info.Env = validEnv(contextName, ext.Env)
+	}
+	return info
+}
+
+// validExtensionValues returns the entries of a map from the extension of a context that pass
+// check, warning about and dropping the others.
+func validExtensionValues(contextName, field string, values map[string]string, check func(key, value string) error) map[string]string {
+	var valid map[string]string
+	for key, value := range values {
+		if err := check(key, value); err != nil {
+			log.WithField("context", contextName).Warnf("Ignoring invalid %s in %s extension: %v", field, extensionName, err)
+			continue
+		}
+		if valid == nil {
+			valid = make(map[string]string)
+		}
+		valid[key] = value
+	}
+	return valid
+}
+
+// validEnv drops environment variables that shells cannot set from the extension of a context.
+func validEnv(contextName string, env map[string]string) map[string]string {
+	return validExtensionValues(contextName, "environment variable", env, func(name, _ string) error {
+		if !config.IsEnvName(name) {
+			return fmt.Errorf("'%s' is not a valid name", name)
+		}
+		return nil
+	})
+}
+
+// validLabels drops labels that would break label selectors from the extension of a context.
+func validLabels(contextName string, set map[string]string) map[string]string {
+	return validExtensionValues(contextName, "label", set, func(key, value string) error {
+		_, err := labels.ValidatedSelectorFromSet(labels.Set{key: value})
+		return err
+	})
+}
+
 // LoadContexts scans the config directory for kubeconfig files and loads all available contexts.
 func (m *Manager) LoadContexts() error {
 	m.contextMap = make(map[string]string)
+	m.contextInfo = make(map[string]ContextInfo)
 	m.contextNames = nil
 
 	files, err := os.ReadDir(m.kubeconfigDir)
@@ -186,41 +436,17 @@ func (m *Manager) LoadContexts() error {
 			continue
 		}
 
-		for contextName := range kubeconfig.Contexts {
+		for _, contextName := range contextsInFileOrder(path, kubeconfig) {
 			if existingPath, exists := m.contextMap[contextName]; exists {
 				log.Warnf("Duplicate context name '%s' found in files:\n  - %s\n  - %s",
 					contextName, existingPath, path)
 				continue
 			}
 			m.contextMap[contextName] = path
+			m.contextInfo[contextName] = newContextInfo(contextName, path, kubeconfig)
 			m.contextNames = append(m.contextNames, contextName)
 		}
 	}
 
 	return nil
 }
-
-// LoadNamespaces loads all namespaces from the current Kubernetes cluster.
-func (m *Manager) LoadNamespaces() error {
-	config, err := clientcmd.BuildConfigFromFlags("", m.kubeconfigPath)
-	if err != nil {
-		return fmt.Errorf("failed to build config: %w", err)
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return fmt.Errorf("failed to create clientset: %w", err)
-	}
-
-	namespaces, err := clientset.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to list namespaces: %w", err)
-	}
-
-	m.namespaceNames = make([]string, 0, len(namespaces.Items))
-	for _, ns := range namespaces.Items {
-		m.namespaceNames = append(m.namespaceNames, ns.Name)
-	}
-
-	return nil
-}
@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// testKubeconfig returns a kubeconfig file defining the context, cluster and user of the given
+// name, with the context as the current one.
+func testKubeconfig(name string) string {
+	return strings.ReplaceAll(lintFile, "prod", name)
+}
+
+// newTestManager returns a manager for a kubeconfig directory with a file for each of the given
+// contexts, and an active kubeconfig switched to the first of them.
+func newTestManager(t *testing.T, contextNames ...string) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range contextNames {
+		if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(testKubeconfig(name)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := NewManager(filepath.Join(t.TempDir(), "config"), dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.LoadContexts(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(m.kubeconfigPath, []byte(testKubeconfig(contextNames[0])), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestRevert(t *testing.T) {
+	tests := []struct {
+		name string
+		// Context the previous-config backup points at, if there is one
+		backup      string
+		contextName string
+		namespace   string
+		wantCurrent string
+		wantNs      string
+		wantBackup  string
+		wantErr     bool
+	}{
+		{name: "previous context", backup: "dev", contextName: "dev", wantCurrent: "dev", wantBackup: "dev"},
+		{name: "previous namespace", contextName: "dev", namespace: "payments", wantCurrent: "dev", wantNs: "payments"},
+		{name: "removes backup of left context", backup: "prod", contextName: "dev", wantCurrent: "dev"},
+		{name: "clears", contextName: ""},
+		{name: "clears and removes backup of left context", backup: "prod", contextName: ""},
+		{name: "clears and keeps other backup", backup: "staging", contextName: "", wantBackup: "staging"},
+		{name: "unknown context", backup: "prod", contextName: "gone", wantCurrent: "prod", wantBackup: "prod", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t, "prod", "dev", "staging")
+			if tt.backup != "" {
+				if err := os.WriteFile(m.backupPath, []byte(testKubeconfig(tt.backup)), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := m.Revert("prod", tt.contextName, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Revert() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if got := m.GetCurrentContext(); got != tt.wantCurrent {
+				t.Errorf("current context = %q, want %q", got, tt.wantCurrent)
+			}
+			if got := m.GetCurrentNamespace(); got != tt.wantNs {
+				t.Errorf("current namespace = %q, want %q", got, tt.wantNs)
+			}
+			if got, _ := m.GetPreviousContext(); got != tt.wantBackup {
+				t.Errorf("previous context = %q, want %q", got, tt.wantBackup)
+			}
+
+			// The credentials of the left context must be gone unless reverting failed
+			active, err := clientcmd.LoadFromFile(m.kubeconfigPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, exists := active.AuthInfos["prod"]; exists != tt.wantErr {
+				t.Errorf("credentials of the left context in the active kubeconfig: %v, want %v", exists, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   map[string]string
+	}{
+		{"none", nil, nil},
+		{"valid", map[string]string{"env": "prod", "app.kubernetes.io/team": "payments", "empty": ""}, map[string]string{"env": "prod", "app.kubernetes.io/team": "payments", "empty": ""}},
+		{"invalid key", map[string]string{"bad key": "x", "env": "prod"}, map[string]string{"env": "prod"}},
+		{"invalid value", map[string]string{"team": "not valid", "env": "prod"}, map[string]string{"env": "prod"}},
+		{"selector syntax", map[string]string{"env": "prod,team=payments"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validLabels("prod", tt.labels); !maps.Equal(got, tt.want) {
+				t.Errorf("validLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{"none", nil, nil},
+		{"valid", map[string]string{"AWS_PROFILE": "prod", "_private": "1", "lower9": ""}, map[string]string{"AWS_PROFILE": "prod", "_private": "1", "lower9": ""}},
+		{"invalid names", map[string]string{"BAD;rm -rf ~": "x", "1PASSWORD": "x", "": "x", "AWS_PROFILE": "prod"}, map[string]string{"AWS_PROFILE": "prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validEnv("prod", tt.env); !maps.Equal(got, tt.want) {
+				t.Errorf("validEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
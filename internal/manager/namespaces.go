@@ -0,0 +1,441 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// LoadNamespaces loads all namespaces that match the given label selector from the cluster of the
+// given context, or of the current context if contextName is empty. An empty selector matches
+// every namespace.
+func (m *Manager) LoadNamespaces(ctx context.Context, contextName, selector string) error {
+	clientset, config, err := m.newClientset(contextName)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	if _, err := m.listNamespaces(ctx, clientset, selector, func(page []Namespace) {
+		for _, ns := range page {
+			names = append(names, ns.Name)
+		}
+	}); err != nil {
+		return clusterError(ctx, config.Host, "failed to list namespaces", err)
+	}
+
+	m.namespaceNames = names
+	return nil
+}
+
+// ValidateNamespace checks that the given namespace exists in the cluster of the given context
+// (or the current one), returning a NotFoundError with the closest matches if it does not.
+func (m *Manager) ValidateNamespace(ctx context.Context, contextName, namespace string) error {
+	if err := m.LoadNamespaces(ctx, contextName, ""); err != nil {
+		return err
+	}
+	if slices.Contains(m.namespaceNames, namespace) {
+		return nil
+	}
+	return newNotFoundError("namespace", namespace, m.namespaceNames)
+}
+
+// CreateNamespace creates a namespace with the given labels and annotations in the cluster of the
+// given context (or the current one). It reports whether the namespace was created, returning
+// false without an error if it already existed.
+func (m *Manager) CreateNamespace(ctx context.Context, contextName, name string, labels, annotations map[string]string) (bool, error) {
+	clientset, config, err := m.newClientset(contextName)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := m.withRequestTimeout(ctx)
+	defer cancel()
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+	if _, err := clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, clusterError(ctx, config.Host, "failed to create namespace", err)
+	}
+
+	return true, nil
+}
+
+// ContextNamespaces holds the namespaces listed from the cluster of a single context.
+type ContextNamespaces struct {
+	Context    string
+	Namespaces []string
+	Err        error
+}
+
+// LoadNamespacesAcross lists the namespaces matching the given label selector from the clusters
+// of all the given contexts concurrently. Failures are reported per context. The results are in
+// the same order as contextNames.
+func (m *Manager) LoadNamespacesAcross(ctx context.Context, contextNames []string, selector string) []ContextNamespaces {
+	results := make([]ContextNamespaces, len(contextNames))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, contextConcurrency)
+	for i, contextName := range contextNames {
+		results[i].Context = contextName
+
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			clientset, config, err := m.newClientset(contextName)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+
+			names := []string{}
+			if _, err := m.listNamespaces(ctx, clientset, selector, func(page []Namespace) {
+				for _, ns := range page {
+					names = append(names, ns.Name)
+				}
+			}); err != nil {
+				results[i].Err = clusterError(ctx, config.Host, "failed to list namespaces", err)
+				return
+			}
+			results[i].Namespaces = names
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Namespace holds the details of a namespace shown when picking one.
+type Namespace struct {
+	Name    string
+	Phase   string
+	Created time.Time
+	Labels  map[string]string
+}
+
+// NamespaceEvent reports a change observed by WatchNamespaces.
+type NamespaceEvent struct {
+	// Added holds namespaces that appeared or whose details changed.
+	Added   []Namespace
+	Removed []string
+	// Synced is set once the initial listing has been fully delivered.
+	Synced bool
+}
+
+// WatchNamespaces streams the namespaces that match the given label selector from the cluster of
+// the given context (or the current one) to handler, first page by page as they are listed and
+// then as they are created, updated or deleted. It blocks until ctx is done. Only failures of the
+// initial listing are returned; once synced, the watch is re-established in the background
+// whenever it drops.
+func (m *Manager) WatchNamespaces(ctx context.Context, contextName, selector string, handler func(NamespaceEvent)) error {
+	clientset, config, err := m.newClientset(contextName)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	resourceVersion, err := m.listNamespaces(ctx, clientset, selector, func(page []Namespace) {
+		for _, ns := range page {
+			known[ns.Name] = true
+		}
+		handler(NamespaceEvent{Added: page})
+	})
+	if err != nil {
+		return clusterError(ctx, config.Host, "failed to list namespaces", err)
+	}
+	handler(NamespaceEvent{Synced: true})
+
+	// Watches are long-lived, so they must not be cut short by the per-request timeout
+	watchConfig := rest.CopyConfig(config)
+	watchConfig.Timeout = 0
+	watchClientset, err := kubernetes.NewForConfig(watchConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		if resourceVersion == "" {
+			resourceVersion = m.relistNamespaces(ctx, clientset, selector, known, handler)
+			if resourceVersion == "" {
+				sleepContext(ctx, watchRetryDelay)
+				continue
+			}
+		}
+
+		w, err := watchClientset.CoreV1().Namespaces().Watch(ctx, metav1.ListOptions{
+			LabelSelector:       selector,
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			log.Debugf("Failed to watch namespaces: %v", err)
+			resourceVersion = ""
+			sleepContext(ctx, watchRetryDelay)
+			continue
+		}
+		resourceVersion = consumeNamespaceWatch(w, resourceVersion, known, handler)
+		w.Stop()
+	}
+
+	return nil
+}
+
+// CountPods counts the pods in each of the given namespaces in the cluster of the given context
+// (or the current one), querying several namespaces concurrently and reporting each count to
+// handler as it arrives. Namespaces whose pods cannot be listed are skipped.
+func (m *Manager) CountPods(ctx context.Context, contextName string, namespaces []string, handler func(namespace string, pods int)) error {
+	clientset, _, err := m.newClientset(contextName)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, podCountConcurrency)
+	for _, namespace := range namespaces {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			reqCtx, cancel := m.withRequestTimeout(ctx)
+			defer cancel()
+
+			// Serve the listing from the API server cache; an exact count is not needed
+			pods, err := clientset.CoreV1().Pods(namespace).List(reqCtx, metav1.ListOptions{ResourceVersion: "0"})
+			if err != nil {
+				log.Debugf("Failed to count pods in namespace '%s': %v", namespace, err)
+				return
+			}
+			handler(namespace, len(pods.Items))
+		})
+	}
+	wg.Wait()
+
+	return nil
+}
+
+const (
+	// watchRetryDelay is how long WatchNamespaces waits before re-establishing a dropped watch.
+	watchRetryDelay = time.Second
+	// podCountConcurrency bounds the number of concurrent pod listings in CountPods.
+	podCountConcurrency = 8
+	// contextConcurrency bounds the number of clusters queried at once across contexts.
+	contextConcurrency = 16
+)
+
+// listNamespaces lists all namespaces page by page, passing each page to onPage, and returns
+// the resource version of the listing.
+func (m *Manager) listNamespaces(ctx context.Context, clientset kubernetes.Interface, selector string, onPage func([]Namespace)) (string, error) {
+	ctx, cancel := m.withRequestTimeout(ctx)
+	defer cancel()
+
+	opts := metav1.ListOptions{LabelSelector: selector, Limit: 250}
+	for {
+		namespaces, err := clientset.CoreV1().Namespaces().List(ctx, opts)
+		if err != nil {
+			return "", err
+		}
+
+		page := make([]Namespace, 0, len(namespaces.Items))
+		for i := range namespaces.Items {
+			page = append(page, newNamespace(&namespaces.Items[i]))
+		}
+		onPage(page)
+
+		if namespaces.Continue == "" {
+			return namespaces.ResourceVersion, nil
+		}
+		opts.Continue = namespaces.Continue
+	}
+}
+
+// relistNamespaces lists namespaces again after a watch could not be resumed and reports the
+// difference to the previously known set. It returns an empty resource version on failure.
+func (m *Manager) relistNamespaces(ctx context.Context, clientset kubernetes.Interface, selector string, known map[string]bool, handler func(NamespaceEvent)) string {
+	var event NamespaceEvent
+	current := make(map[string]bool)
+	resourceVersion, err := m.listNamespaces(ctx, clientset, selector, func(page []Namespace) {
+		for _, ns := range page {
+			current[ns.Name] = true
+		}
+		event.Added = append(event.Added, page...)
+	})
+	if err != nil {
+		log.Debugf("Failed to relist namespaces: %v", err)
+		return ""
+	}
+
+	for name := range known {
+		if !current[name] {
+			event.Removed = append(event.Removed, name)
+		}
+	}
+	handler(event)
+
+	for name := range known {
+		delete(known, name)
+	}
+	for name := range current {
+		known[name] = true
+	}
+	return resourceVersion
+}
+
+// consumeNamespaceWatch forwards watch events to handler until the watch ends and returns the
+// resource version to resume from, or an empty string if a relist is required.
+func consumeNamespaceWatch(w watch.Interface, resourceVersion string, known map[string]bool, handler func(NamespaceEvent)) string {
+	for event := range w.ResultChan() {
+		if event.Type == watch.Error {
+			return ""
+		}
+
+		ns, ok := event.Object.(*corev1.Namespace)
+		if !ok {
+			continue
+		}
+		resourceVersion = ns.ResourceVersion
+
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			known[ns.Name] = true
+			handler(NamespaceEvent{Added: []Namespace{newNamespace(ns)}})
+		case watch.Deleted:
+			if known[ns.Name] {
+				delete(known, ns.Name)
+				handler(NamespaceEvent{Removed: []string{ns.Name}})
+			}
+		}
+	}
+	return resourceVersion
+}
+
+// newNamespace extracts the details shown when picking a namespace.
+func newNamespace(ns *corev1.Namespace) Namespace {
+	return Namespace{
+		Name:    ns.Name,
+		Phase:   string(ns.Status.Phase),
+		Created: ns.CreationTimestamp.Time,
+		Labels:  ns.Labels,
+	}
+}
+
+// newClientset builds a Kubernetes clientset for the given context, or for the currently active
+// kubeconfig if contextName is empty.
+func (m *Manager) newClientset(contextName string) (*kubernetes.Clientset, *rest.Config, error) {
+	config, err := m.restConfig(contextName)
+	if err != nil {
+		return nil, nil, err
+	}
+	config.Timeout = m.requestTimeout
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return clientset, config, nil
+}
+
+// restConfig builds the client configuration for the given context from the kubeconfig file it
+// was loaded from, or for the currently active kubeconfig if contextName is empty.
+func (m *Manager) restConfig(contextName string) (*rest.Config, error) {
+	if contextName == "" {
+		config, err := clientcmd.BuildConfigFromFlags("", m.kubeconfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build config: %w", err)
+		}
+		return config, nil
+	}
+
+	kubeconfig, err := m.loadContextKubeconfig(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := clientcmd.NewNonInteractiveClientConfig(*kubeconfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config for context '%s': %w", contextName, err)
+	}
+	return config, nil
+}
+
+// loadContextKubeconfig loads the kubeconfig file the given context was found in, with relative
+// paths in it resolved against the file's directory.
+func (m *Manager) loadContextKubeconfig(contextName string) (*clientcmdapi.Config, error) {
+	contextFilePath, exists := m.contextMap[contextName]
+	if !exists {
+		return nil, newNotFoundError("context", contextName, m.contextNames)
+	}
+
+	kubeconfig, err := clientcmd.LoadFromFile(contextFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig from %s: %w", contextFilePath, err)
+	}
+	if err := clientcmd.ResolveLocalPaths(kubeconfig); err != nil {
+		return nil, fmt.Errorf("failed to resolve paths in %s: %w", contextFilePath, err)
+	}
+	return kubeconfig, nil
+}
+
+// withRequestTimeout bounds ctx by the configured request timeout, if any.
+func (m *Manager) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.requestTimeout)
+}
+
+// clusterError turns a failed cluster request into a user-facing error, calling out requests
+// that never got a response from the API server.
+func clusterError(ctx context.Context, host, action string, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return fmt.Errorf("%s: interrupted", action)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("cluster unreachable at %s: no response within the request timeout", host)
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("cluster unreachable at %s: %w", host, urlErr.Err)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,90 @@
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxSuggestions is the number of alternatives offered for a name that was not found.
+const maxSuggestions = 3
+
+// NotFoundError is returned when a context or namespace given by name does not exist.
+type NotFoundError struct {
+	Kind        string
+	Name        string
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	msg := fmt.Sprintf("%s '%s' not found", e.Kind, e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean '%s'?", strings.Join(e.Suggestions, "', '"))
+	}
+	return msg
+}
+
+// newNotFoundError builds a NotFoundError, suggesting the candidates closest to name.
+func newNotFoundError(kind, name string, candidates []string) *NotFoundError {
+	return &NotFoundError{Kind: kind, Name: name, Suggestions: suggest(name, candidates)}
+}
+
+// suggest returns the candidates closest to name by edit distance, best match first. Candidates
+// that contain name are always considered close; others only if few enough edits away.
+func suggest(name string, candidates []string) []string {
+	type scored struct {
+		candidate string
+		distance  int
+	}
+
+	lowerName := strings.ToLower(name)
+	threshold := max(2, len(name)/3)
+
+	var matches []scored
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		distance := levenshtein(lowerName, lower)
+		if distance <= threshold || strings.Contains(lower, lowerName) {
+			matches = append(matches, scored{candidate, distance})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b scored) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.candidate, b.candidate)
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, match := range matches {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, match.candidate)
+	}
+	return suggestions
+}
+
+// levenshtein computes the number of single-character edits needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"prod", "prod", 0},
+		{"prod", "prd", 1},
+		{"prod", "prods", 1},
+		{"prod", "brod", 1},
+		{"kitten", "sitting", 3},
+		{"über", "uber", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := levenshtein(tt.a, tt.b); got != tt.want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"payments-prod", "payments-staging", "orders-prod", "kube-system", "default"}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"payments-prd", []string{"payments-prod"}},
+		{"payments", []string{"payments-prod", "payments-staging"}},
+		{"PROD", []string{"orders-prod", "payments-prod"}},
+		{"defualt", []string{"default"}},
+		{"kube-sytem", []string{"kube-system"}},
+		{"monitoring", []string{}},
+		{"-", []string{"kube-system", "orders-prod", "payments-prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggest(tt.name, candidates); !slices.Equal(got, tt.want) {
+				t.Errorf("suggest(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotFoundError(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{"prd", []string{"prod", "dev"}, "context 'prd' not found, did you mean 'prod'?"},
+		{"prd", nil, "context 'prd' not found"},
+		{"stage", []string{"stage-us", "staging", "stage-eu"}, "context 'stage' not found, did you mean 'stage-eu', 'stage-us'?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := newNotFoundError("context", tt.name, tt.candidates).Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State holds data that kubectl-switch accumulates across invocations.
+type State struct {
+	path string
+
+	// Contexts records how often and how recently each context was switched to
+	Contexts map[string]Usage `json:"contexts,omitempty"`
+	// Namespaces records namespace usage per context
+	Namespaces map[string]map[string]Usage `json:"namespaces,omitempty"`
+	// ProtectedSwitches records the most recent confirmed switches to protected contexts
+	ProtectedSwitches []ProtectedSwitch `json:"protectedSwitches,omitempty"`
+	// Lease is the active time-boxed switch, if any
+	Lease *Lease `json:"lease,omitempty"`
+}
+
+// Lease records a time-boxed switch to a context, and what to revert to once it expires.
+type Lease struct {
+	Context           string    `json:"context"`
+	Expires           time.Time `json:"expires"`
+	PreviousContext   string    `json:"previousContext,omitempty"`
+	PreviousNamespace string    `json:"previousNamespace,omitempty"`
+}
+
+// ProtectedSwitch records a confirmed switch to a protected context.
+type ProtectedSwitch struct {
+	Context string    `json:"context"`
+	Reason  string    `json:"reason,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// maxProtectedSwitches is the number of protected switches kept in the state file.
+const maxProtectedSwitches = 100
+
+// Usage records how often and when something was last switched to.
+type Usage struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// DefaultPath returns the location of the state file, following the XDG base directory spec.
+func DefaultPath() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(os.Getenv("HOME"), ".local", "state")
+	}
+	return filepath.Join(stateHome, "kubectl-switch", "state.json")
+}
+
+// Load reads the state file at path. A missing file yields an empty state.
+func Load(path string) (*State, error) {
+	s := &State{
+		path:       path,
+		Contexts:   make(map[string]Usage),
+		Namespaces: make(map[string]map[string]Usage),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return s, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if s.Contexts == nil {
+		s.Contexts = make(map[string]Usage)
+	}
+	if s.Namespaces == nil {
+		s.Namespaces = make(map[string]map[string]Usage)
+	}
+	return s, nil
+}
+
+// Save writes the state back to its file, replacing it atomically.
+func (s *State) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Use a temporary file of our own, so concurrent invocations never write to the same one
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "state-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// RecordContext notes that the given context was switched to.
+func (s *State) RecordContext(contextName string, now time.Time) {
+	s.Contexts[contextName] = s.Contexts[contextName].record(now)
+}
+
+// RecordNamespace notes that the given namespace of a context was switched to.
+func (s *State) RecordNamespace(contextName, namespace string, now time.Time) {
+	if s.Namespaces[contextName] == nil {
+		s.Namespaces[contextName] = make(map[string]Usage)
+	}
+	s.Namespaces[contextName][namespace] = s.Namespaces[contextName][namespace].record(now)
+}
+
+// RecordProtectedSwitch notes a confirmed switch to a protected context, dropping the oldest
+// records beyond the most recent ones.
+func (s *State) RecordProtectedSwitch(contextName, reason string, now time.Time) {
+	s.ProtectedSwitches = append(s.ProtectedSwitches, ProtectedSwitch{Context: contextName, Reason: reason, Time: now})
+	if excess := len(s.ProtectedSwitches) - maxProtectedSwitches; excess > 0 {
+		s.ProtectedSwitches = s.ProtectedSwitches[excess:]
+	}
+}
+
+func (u Usage) record(now time.Time) Usage {
+	return Usage{Count: u.Count + 1, LastUsed: now}
+}
+
+// Frecency scores usage by combining how often and how recently something was used, so that
+// frequently used entries rank high but fade once they stop being used.
+func (u Usage) Frecency(now time.Time) float64 {
+	if u.Count == 0 {
+		return 0
+	}
+
+	age := now.Sub(u.LastUsed)
+	switch {
+	case age < time.Hour:
+		return float64(u.Count) * 4
+	case age < 24*time.Hour:
+		return float64(u.Count) * 2
+	case age < 7*24*time.Hour:
+		return float64(u.Count) / 2
+	default:
+		return float64(u.Count) / 4
+	}
+}
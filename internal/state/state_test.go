@@ -0,0 +1,66 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		usage Usage
+		want  float64
+	}{
+		{"unused", Usage{}, 0},
+		{"just now", Usage{Count: 3, LastUsed: now}, 12},
+		{"within the hour", Usage{Count: 3, LastUsed: now.Add(-59 * time.Minute)}, 12},
+		{"an hour ago", Usage{Count: 3, LastUsed: now.Add(-time.Hour)}, 6},
+		{"within the day", Usage{Count: 3, LastUsed: now.Add(-23 * time.Hour)}, 6},
+		{"a day ago", Usage{Count: 4, LastUsed: now.Add(-24 * time.Hour)}, 2},
+		{"within the week", Usage{Count: 4, LastUsed: now.Add(-6 * 24 * time.Hour)}, 2},
+		{"a week ago", Usage{Count: 4, LastUsed: now.Add(-7 * 24 * time.Hour)}, 1},
+		{"long ago", Usage{Count: 4, LastUsed: now.Add(-365 * 24 * time.Hour)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.usage.Frecency(now); got != tt.want {
+				t.Errorf("Frecency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kubectl-switch", "state.json")
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file failed: %v", err)
+	}
+	s.RecordContext("prod", now)
+	s.RecordContext("prod", now)
+	s.RecordNamespace("prod", "payments", now)
+	if err := s.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if got := loaded.Contexts["prod"]; got.Count != 2 || !got.LastUsed.Equal(now) {
+		t.Errorf("context usage = %+v, want 2 uses at %v", got, now)
+	}
+	if got := loaded.Namespaces["prod"]["payments"].Count; got != 1 {
+		t.Errorf("namespace usage count = %d, want 1", got)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), "state-*.json"))
+	if err != nil || len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+var errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // red
+
+// InputModel represents a single-line text input prompt
+type InputModel struct {
+	message  string
+	hint     string
+	value    string
+	validate func(value string) error
+	err      error
+	quitting bool
+	aborted  bool
+}
+
+// NewInputModel creates a new text input model. If validate is not nil, the input is only
+// accepted once it returns no error for it.
+func NewInputModel(message, hint string, validate func(value string) error) InputModel {
+	return InputModel{
+		message:  message,
+		hint:     hint,
+		validate: validate,
+	}
+}
+
+// Init implements tea.Model
+func (m InputModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m InputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.PasteMsg:
+		m.value += msg.Content
+		m.err = nil
+	case tea.KeyPressMsg:
+		switch {
+		case key.Matches(msg, keys.Quit), key.Matches(msg, keys.Escape):
+			m.aborted = true
+			m.quitting = true
+			return m, tea.Quit
+
+		case key.Matches(msg, keys.Enter):
+			if m.validate != nil {
+				if m.err = m.validate(m.value); m.err != nil {
+					return m, nil
+				}
+			}
+			m.quitting = true
+			return m, tea.Quit
+
+		case msg.String() == "backspace":
+			if runes := []rune(m.value); len(runes) > 0 {
+				m.value = string(runes[:len(runes)-1])
+			}
+			m.err = nil
+
+		default:
+			if text := msg.Key().Text; text != "" {
+				m.value += text
+				m.err = nil
+			}
+		}
+	}
+	return m, nil
+}
+
+// View implements tea.Model
+func (m InputModel) View() tea.View {
+	if m.quitting {
+		return tea.NewView("")
+	}
+
+	var b strings.Builder
+	b.WriteString(promptStyle.Render(m.message))
+	b.WriteString(" ")
+	if m.value != "" {
+		b.WriteString(filterStyle.Render(m.value))
+	} else {
+		b.WriteString(hintStyle.Render(m.hint))
+	}
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString(errorStyle.Render("  " + m.err.Error()))
+		b.WriteString("\n")
+	}
+	return tea.NewView(b.String())
+}
+
+// Value returns the entered text
+func (m InputModel) Value() string {
+	return m.value
+}
+
+// Aborted returns true if the user aborted the input
+func (m InputModel) Aborted() bool {
+	return m.aborted
+}
+
+// Input runs an interactive text input prompt and returns the entered text
+func Input(message, hint string, validate func(value string) error) (string, error) {
+	p := tea.NewProgram(NewInputModel(message, hint, validate), programOptions()...)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return "", fmt.Errorf("failed to run input: %w", err)
+	}
+
+	result := finalModel.(InputModel)
+	if result.Aborted() {
+		return "", fmt.Errorf("input aborted")
+	}
+	return result.Value(), nil
+}
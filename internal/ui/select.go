@@ -1,19 +1,46 @@
 package ui
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strings"
 
 	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/spinner"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 )
 
+// Option is a selectable entry, optionally carrying extra cells shown next to its value
+type Option struct {
+	Value string
+	Cells []Cell
+}
+
+// Cell is a piece of extra information shown in a column next to an option
+type Cell struct {
+	Text string
+	// Key orders cells when sorting by their column; cells with equal keys sort by Text
+	Key int64
+}
+
+// Column describes an extra column shown in the selection prompt
+type Column struct {
+	Title string
+}
+
 // SelectModel represents a selection list component
 type SelectModel struct {
 	message         string
-	options         []string
-	filteredOptions []string
+	options         []Option
+	filteredOptions []Option
+	columns         []Column
+	sortBy          int
+	ranking         Ranking
+	aliases         func(value string) []string
+	labels          func(value string) string
+	tags            func(value string) []Tag
 	filter          string
 	current         string
 	cursor          int
@@ -23,6 +50,11 @@ type SelectModel struct {
 	selected        string
 	quitting        bool
 	aborted         bool
+	loading         bool
+	create          func(value string) error
+	creating        bool
+	spinner         spinner.Model
+	err             error
 }
 
 // Styles for the select component
@@ -30,6 +62,7 @@ var (
 	cursorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))   // cyan
 	normalStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("250")) // light gray
 	currentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))   // magenta
+	pinnedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))   // yellow
 
 	// Prompt styles
 	promptStyle = lipgloss.NewStyle().
@@ -62,6 +95,41 @@ func fuzzyMatch(str, pattern string) bool {
 	return patternIdx == len(pattern)
 }
 
+// matchesFilter checks an option against every whitespace-separated term of the filter.
+// Terms of the form column=pattern are matched against that column's cell, where column is
+// a case-insensitive prefix of the column title; all other terms are matched against the value
+// and the other names it is known by, such as its label and aliases.
+func matchesFilter(opt Option, columns []Column, names []string, filter string) bool {
+	for _, term := range strings.Fields(filter) {
+		texts := append([]string{opt.Value}, names...)
+		if name, pattern, found := strings.Cut(term, "="); found && name != "" {
+			idx := columnIndex(columns, name)
+			if idx < 0 {
+				return false
+			}
+			texts, term = []string{""}, pattern
+			if idx < len(opt.Cells) {
+				texts = []string{opt.Cells[idx].Text}
+			}
+		}
+		if !slices.ContainsFunc(texts, func(text string) bool { return fuzzyMatch(text, term) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// columnIndex returns the first column whose title starts with name, or -1
+func columnIndex(columns []Column, name string) int {
+	name = strings.ToLower(name)
+	for i, col := range columns {
+		if strings.HasPrefix(strings.ToLower(col.Title), name) {
+			return i
+		}
+	}
+	return -1
+}
+
 // Key bindings
 type keyMap struct {
 	Up     key.Binding
@@ -74,6 +142,7 @@ type keyMap struct {
 	PgDown key.Binding
 	Home   key.Binding
 	End    key.Binding
+	Sort   key.Binding
 }
 
 var keys = keyMap{
@@ -117,32 +186,116 @@ var keys = keyMap{
 		key.WithKeys("end"),
 		key.WithHelp("end", "go to end"),
 	),
+	Sort: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "cycle sort column"),
+	),
+}
+
+// SelectOption customizes a selection prompt
+type SelectOption func(*SelectModel)
+
+// WithQuery pre-fills the filter of the selection prompt
+func WithQuery(query string) SelectOption {
+	return func(m *SelectModel) {
+		m.filter = query
+	}
+}
+
+// WithCreate offers to create the filter text as a new option when nothing matches it. If the
+// user chooses to, create is called with the text once the prompt has closed, and the prompt
+// returns the text as its selection.
+func WithCreate(create func(value string) error) SelectOption {
+	return func(m *SelectModel) {
+		m.create = create
+	}
+}
+
+// Ranking decides the order of options in the selection prompt's default sort mode
+type Ranking struct {
+	// Pinned options are listed first in every sort mode and marked as such
+	Pinned func(value string) bool
+	// Score orders the remaining options, highest first; options with equal scores keep their order
+	Score func(value string) float64
+}
+
+// WithRanking orders options by the given ranking instead of the order they were given in
+func WithRanking(ranking Ranking) SelectOption {
+	return func(m *SelectModel) {
+		m.ranking = ranking
+	}
+}
+
+// WithSortByValue initially sorts the options by their value
+func WithSortByValue() SelectOption {
+	return func(m *SelectModel) {
+		m.sortBy = 0
+	}
+}
+
+// WithAliases shows the aliases of each option next to its value and lets the filter match them
+func WithAliases(aliases func(value string) []string) SelectOption {
+	return func(m *SelectModel) {
+		m.aliases = aliases
+	}
+}
+
+// WithLabels shows the label of each option instead of its value, and lets the filter match it.
+// Options without a label are shown by their value.
+func WithLabels(labels func(value string) string) SelectOption {
+	return func(m *SelectModel) {
+		m.labels = labels
+	}
 }
 
 // NewSelectModel creates a new selection model
-func NewSelectModel(message string, options []string, current string, pageSize int) SelectModel {
+func NewSelectModel(message string, options []string, current string, pageSize int, opts ...SelectOption) SelectModel {
+	return NewTableSelectModel(message, nil, stringOptions(options), current, pageSize, opts...)
+}
+
+// NewTableSelectModel creates a new selection model whose options carry one cell per column
+func NewTableSelectModel(message string, columns []Column, options []Option, current string, pageSize int, opts ...SelectOption) SelectModel {
 	if pageSize <= 0 {
 		pageSize = 10
 	}
-	// Initialize filteredOptions as a copy of options
-	filteredOptions := make([]string, len(options))
-	copy(filteredOptions, options)
 
-	return SelectModel{
-		message:         message,
-		options:         options,
-		filteredOptions: filteredOptions,
-		filter:          "",
-		current:         current,
-		cursor:          0,
-		pageSize:        pageSize,
-		offset:          0,
-		width:           80,
+	m := SelectModel{
+		message:  message,
+		options:  options,
+		columns:  columns,
+		sortBy:   -1,
+		filter:   "",
+		current:  current,
+		cursor:   0,
+		pageSize: pageSize,
+		offset:   0,
+		width:    80,
+		spinner:  spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(hintStyle)),
+	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	// Initialize filteredOptions from the options and any pre-filled filter
+	m.updateFilter()
+	return m
+}
+
+// Match returns the values that the selection prompt would show for the given filter
+func Match(values []string, filter string, opts ...SelectOption) []string {
+	m := NewSelectModel("", values, "", 0, append(slices.Clip(opts), WithQuery(filter))...)
+	matches := make([]string, len(m.filteredOptions))
+	for i, opt := range m.filteredOptions {
+		matches[i] = opt.Value
 	}
+	return matches
 }
 
 // Init implements tea.Model
 func (m SelectModel) Init() tea.Cmd {
+	if m.loading {
+		return m.spinner.Tick
+	}
 	return nil
 }
 
@@ -151,6 +304,20 @@ func (m SelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
+	case spinner.TickMsg:
+		if m.loading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+	case optionsMsg:
+		m.applyOptions(msg)
+	case syncedMsg:
+		m.loading = false
+	case loadErrMsg:
+		m.err = msg.err
+		m.quitting = true
+		return m, tea.Quit
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, keys.Quit):
@@ -170,17 +337,31 @@ func (m SelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, keys.Enter):
 			if len(m.filteredOptions) > 0 {
-				m.selected = m.filteredOptions[m.cursor]
+				m.selected = m.filteredOptions[m.cursor].Value
+				m.quitting = true
+				return m, tea.Quit
+			}
+			if m.canCreate() {
+				m.selected = m.createValue()
+				m.creating = true
 				m.quitting = true
 				return m, tea.Quit
 			}
 
 		case key.Matches(msg, keys.Right):
 			if len(m.filteredOptions) > 0 {
-				m.filter = m.filteredOptions[m.cursor]
+				m.filter = m.filteredOptions[m.cursor].Value
 				m.updateFilter()
 			}
 
+		case key.Matches(msg, keys.Sort):
+			// Cycle through: original order, value, then each column
+			m.sortBy++
+			if m.sortBy > len(m.columns) {
+				m.sortBy = -1
+			}
+			m.updateFilter()
+
 		case key.Matches(msg, keys.Up):
 			if len(m.filteredOptions) > 0 {
 				if m.cursor > 0 {
@@ -247,19 +428,172 @@ func (m SelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// updateFilter updates the filtered options based on the current filter
+// updateFilter updates the filtered options based on the current filter and sort order
 func (m *SelectModel) updateFilter() {
 	m.filteredOptions = nil
 	for _, opt := range m.options {
-		if fuzzyMatch(opt, m.filter) {
+		if matchesFilter(opt, m.columns, m.namesOf(opt.Value), m.filter) {
 			m.filteredOptions = append(m.filteredOptions, opt)
 		}
 	}
+	if m.sortBy >= 0 || m.ranking.Pinned != nil || m.ranking.Score != nil {
+		slices.SortStableFunc(m.filteredOptions, m.compareOptions)
+	}
 	// Reset cursor and offset when filter changes
 	m.cursor = 0
 	m.offset = 0
 }
 
+// compareOptions orders two options: pinned options first, then by the active sort column or,
+// in the default sort mode, by score
+func (m *SelectModel) compareOptions(a, b Option) int {
+	if pa, pb := m.isPinned(a.Value), m.isPinned(b.Value); pa != pb {
+		if pa {
+			return -1
+		}
+		return 1
+	}
+
+	if m.sortBy < 0 {
+		if m.ranking.Score == nil {
+			return 0
+		}
+		return cmp.Compare(m.ranking.Score(b.Value), m.ranking.Score(a.Value))
+	}
+	if m.sortBy == 0 {
+		return strings.Compare(a.Value, b.Value)
+	}
+	var ca, cb Cell
+	if idx := m.sortBy - 1; idx < len(a.Cells) {
+		ca = a.Cells[idx]
+	}
+	if idx := m.sortBy - 1; idx < len(b.Cells) {
+		cb = b.Cells[idx]
+	}
+	return cmp.Or(cmp.Compare(ca.Key, cb.Key), strings.Compare(ca.Text, cb.Text), strings.Compare(a.Value, b.Value))
+}
+
+// aliasesOf returns the aliases of the given option value, if any
+func (m *SelectModel) aliasesOf(value string) []string {
+	if m.aliases == nil {
+		return nil
+	}
+	return m.aliases(value)
+}
+
+// labelOf returns the text an option is shown as
+func (m *SelectModel) labelOf(value string) string {
+	if m.labels != nil {
+		if label := m.labels(value); label != "" {
+			return label
+		}
+	}
+	return value
+}
+
+// namesOf returns the names other than its value that the filter matches an option by
+func (m *SelectModel) namesOf(value string) []string {
+	names := m.aliasesOf(value)
+	if label := m.labelOf(value); label != value {
+		names = append(names, label)
+	}
+	for _, tag := range m.tagsOf(value) {
+		if tag.Text != "" {
+			names = append(names, tag.Text)
+		}
+	}
+	return names
+}
+
+// tagsOf returns the tags of the given option value, if any
+func (m *SelectModel) tagsOf(value string) []Tag {
+	if m.tags == nil {
+		return nil
+	}
+	return m.tags(value)
+}
+
+// aliasLabel returns the text shown after an option's label to list its aliases, leaving out
+// those the label already shows
+func (m *SelectModel) aliasLabel(value string) string {
+	label := m.labelOf(value)
+	var aliases []string
+	for _, alias := range m.aliasesOf(value) {
+		if !strings.Contains(label, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) > 0 {
+		return " (" + strings.Join(aliases, ", ") + ")"
+	}
+	return ""
+}
+
+// isPinned reports whether the ranking pins the given value
+func (m *SelectModel) isPinned(value string) bool {
+	return m.ranking.Pinned != nil && m.ranking.Pinned(value)
+}
+
+// canCreate reports whether the filter can be offered as a new option. This is only done once
+// loading has finished, since a matching option might otherwise still show up.
+func (m *SelectModel) canCreate() bool {
+	return m.create != nil && !m.loading && len(m.filteredOptions) == 0 && m.createValue() != ""
+}
+
+// createValue returns the value the filter would create: its only term, or an empty string if it
+// has several terms or filters on a column.
+func (m *SelectModel) createValue() string {
+	terms := strings.Fields(m.filter)
+	if len(terms) != 1 || strings.Contains(terms[0], "=") {
+		return ""
+	}
+	return terms[0]
+}
+
+// applyOptions adds, updates and removes streamed options, keeping the cursor on the same option
+func (m *SelectModel) applyOptions(msg optionsMsg) {
+	var highlighted string
+	if m.cursor < len(m.filteredOptions) {
+		highlighted = m.filteredOptions[m.cursor].Value
+	}
+
+	removed := make(map[string]bool, len(msg.removed))
+	for _, value := range msg.removed {
+		removed[value] = true
+	}
+	updated := make(map[string]Option, len(msg.added))
+	for _, opt := range msg.added {
+		updated[opt.Value] = opt
+	}
+	options := make([]Option, 0, len(m.options)+len(msg.added))
+	for _, opt := range m.options {
+		if removed[opt.Value] {
+			continue
+		}
+		if upd, ok := updated[opt.Value]; ok {
+			opt = upd
+			delete(updated, opt.Value)
+		}
+		options = append(options, opt)
+	}
+	for _, opt := range msg.added {
+		if upd, ok := updated[opt.Value]; ok {
+			options = append(options, upd)
+			delete(updated, opt.Value)
+		}
+	}
+	m.options = options
+
+	m.updateFilter()
+	for i, opt := range m.filteredOptions {
+		if opt.Value == highlighted {
+			m.cursor = i
+			break
+		}
+	}
+	m.adjustOffset()
+}
+
 // adjustOffset ensures the cursor is visible within the page
 func (m *SelectModel) adjustOffset() {
 	if m.cursor < m.offset {
@@ -282,12 +616,17 @@ func (m SelectModel) View() tea.View {
 	leftSide := promptStyle.Render(m.message) + " "
 	if m.filter != "" {
 		leftSide += filterStyle.Render(m.filter)
+	} else if len(m.columns) > 0 {
+		leftSide += hintStyle.Render("Type to filter (column=value), ctrl+s to sort...")
 	} else {
 		leftSide += hintStyle.Render("Type to filter...")
 	}
 
 	// Build right side (counter)
 	rightSide := hintStyle.Render(fmt.Sprintf("(%d/%d)", m.cursor+1, len(m.filteredOptions)))
+	if m.loading {
+		rightSide = m.spinner.View() + " " + rightSide
+	}
 
 	// Calculate padding for right alignment
 	leftLen := lipgloss.Width(leftSide)
@@ -304,7 +643,13 @@ func (m SelectModel) View() tea.View {
 
 	// Handle empty filtered results
 	if len(m.filteredOptions) == 0 {
-		b.WriteString(normalStyle.Render("  No matches found"))
+		if m.canCreate() {
+			b.WriteString(cursorStyle.Render(fmt.Sprintf("> Create '%s'", m.createValue())))
+		} else if m.loading {
+			b.WriteString(normalStyle.Render("  Loading..."))
+		} else {
+			b.WriteString(normalStyle.Render("  No matches found"))
+		}
 		b.WriteString("\n")
 		return tea.NewView(b.String())
 	}
@@ -315,22 +660,71 @@ func (m SelectModel) View() tea.View {
 		end = len(m.filteredOptions)
 	}
 
+	// Size the value and each column to its widest entry so the table does not shift while scrolling
+	widths := make([]int, len(m.columns)+1)
+	for i, col := range m.columns {
+		widths[i+1] = lipgloss.Width(col.Title)
+	}
+	for _, opt := range m.options {
+		widths[0] = max(widths[0], lipgloss.Width(m.labelOf(opt.Value)+m.aliasLabel(opt.Value)))
+		for i, cell := range opt.Cells {
+			if i < len(m.columns) {
+				widths[i+1] = max(widths[i+1], lipgloss.Width(cell.Text))
+			}
+		}
+	}
+
+	// Display column headers
+	if len(m.columns) > 0 {
+		b.WriteString("  ")
+		b.WriteString(m.headerStyle(0).Render(padRight("NAME", widths[0])))
+		for i, col := range m.columns {
+			b.WriteString("  ")
+			b.WriteString(m.headerStyle(i + 1).Render(padRight(strings.ToUpper(col.Title), widths[i+1])))
+		}
+		b.WriteString("\n")
+	}
+
 	// Display options
 	for i := m.offset; i < end; i++ {
 		option := m.filteredOptions[i]
-		isCurrent := option == m.current
+		isCurrent := option.Value == m.current
 
+		style := normalStyle
+		if isCurrent {
+			style = currentStyle
+		}
 		if i == m.cursor {
+			style = cursorStyle
 			b.WriteString(cursorStyle.Render("> "))
-			b.WriteString(cursorStyle.Render(option))
 		} else {
 			b.WriteString("  ")
-			if isCurrent {
-				b.WriteString(currentStyle.Render(option))
-			} else {
-				b.WriteString(normalStyle.Render(option))
+		}
+
+		label, alias := m.labelOf(option.Value), m.aliasLabel(option.Value)
+		if len(m.columns) == 0 {
+			b.WriteString(style.Render(label))
+			b.WriteString(hintStyle.Render(alias))
+		} else {
+			b.WriteString(style.Render(label))
+			b.WriteString(hintStyle.Render(padRight(alias, widths[0]-lipgloss.Width(label))))
+			for c := range m.columns {
+				var text string
+				if c < len(option.Cells) {
+					text = option.Cells[c].Text
+				}
+				b.WriteString("  ")
+				b.WriteString(style.Render(padRight(text, widths[c+1])))
+			}
+		}
+		for _, tag := range m.tagsOf(option.Value) {
+			if tag.Text != "" {
+				b.WriteString(" " + tag.Render())
 			}
 		}
+		if m.isPinned(option.Value) {
+			b.WriteString(pinnedStyle.Render(" ★"))
+		}
 		if isCurrent {
 			b.WriteString(currentStyle.Render(" (current)"))
 		}
@@ -340,6 +734,31 @@ func (m SelectModel) View() tea.View {
 	return tea.NewView(b.String())
 }
 
+// headerStyle returns the style for a column header, highlighting the active sort column
+func (m SelectModel) headerStyle(idx int) lipgloss.Style {
+	if idx == m.sortBy {
+		return hintStyle.Underline(true)
+	}
+	return hintStyle
+}
+
+// padRight pads s with spaces up to the given display width
+func padRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// stringOptions wraps plain values as options without any cells
+func stringOptions(values []string) []Option {
+	options := make([]Option, len(values))
+	for i, v := range values {
+		options[i] = Option{Value: v}
+	}
+	return options
+}
+
 // Selected returns the selected option
 func (m SelectModel) Selected() string {
 	return m.selected
@@ -351,19 +770,32 @@ func (m SelectModel) Aborted() bool {
 }
 
 // Select runs an interactive selection prompt and returns the selected option
-func Select(message string, options []string, current string, pageSize int) (string, error) {
-	model := NewSelectModel(message, options, current, pageSize)
-	p := tea.NewProgram(model)
+func Select(message string, options []string, current string, pageSize int, opts ...SelectOption) (string, error) {
+	model := NewSelectModel(message, options, current, pageSize, opts...)
+	p := tea.NewProgram(model, programOptions()...)
 
 	finalModel, err := p.Run()
 	if err != nil {
 		return "", fmt.Errorf("failed to run selection: %w", err)
 	}
 
-	result := finalModel.(SelectModel)
+	return finishSelect(finalModel.(SelectModel))
+}
+
+// finishSelect returns the outcome of a finished selection prompt, creating the selected option
+// first if the user chose to
+func finishSelect(result SelectModel) (string, error) {
+	if result.err != nil {
+		return "", result.err
+	}
 	if result.Aborted() {
 		return "", fmt.Errorf("selection aborted")
 	}
 
+	if result.creating {
+		if err := result.create(result.selected); err != nil {
+			return "", err
+		}
+	}
 	return result.Selected(), nil
 }
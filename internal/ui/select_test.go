@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApplyOptions(t *testing.T) {
+	option := func(value, phase string) Option {
+		return Option{Value: value, Cells: []Cell{{Text: phase}}}
+	}
+	initial := []Option{option("default", "Active"), option("payments", "Active"), option("orders", "Active")}
+
+	tests := []struct {
+		name        string
+		filter      string
+		cursor      int
+		msg         optionsMsg
+		want        []Option
+		wantCursor  string
+		wantVisible []string
+	}{
+		{
+			name:       "adds options at the end",
+			msg:        optionsMsg{added: []Option{option("billing", "Active")}},
+			want:       append(slices.Clone(initial), option("billing", "Active")),
+			wantCursor: "default",
+		},
+		{
+			name:       "updates options in place",
+			cursor:     2,
+			msg:        optionsMsg{added: []Option{option("payments", "Terminating")}},
+			want:       []Option{option("default", "Active"), option("payments", "Terminating"), option("orders", "Active")},
+			wantCursor: "orders",
+		},
+		{
+			name:       "keeps the latest of repeated options",
+			msg:        optionsMsg{added: []Option{option("billing", "Active"), option("billing", "Terminating")}},
+			want:       append(slices.Clone(initial), option("billing", "Terminating")),
+			wantCursor: "default",
+		},
+		{
+			name:       "keeps the cursor on its option",
+			cursor:     2,
+			msg:        optionsMsg{removed: []string{"default"}},
+			want:       []Option{option("payments", "Active"), option("orders", "Active")},
+			wantCursor: "orders",
+		},
+		{
+			name:       "moves the cursor to the top when its option is removed",
+			cursor:     1,
+			msg:        optionsMsg{removed: []string{"payments"}},
+			want:       []Option{option("default", "Active"), option("orders", "Active")},
+			wantCursor: "default",
+		},
+		{
+			name:       "ignores unknown removals",
+			msg:        optionsMsg{removed: []string{"billing"}},
+			want:       initial,
+			wantCursor: "default",
+		},
+		{
+			name:        "applies the filter to new options",
+			filter:      "pay",
+			msg:         optionsMsg{added: []Option{option("payments-eu", "Active"), option("billing", "Active")}},
+			want:        append(slices.Clone(initial), option("payments-eu", "Active"), option("billing", "Active")),
+			wantCursor:  "payments",
+			wantVisible: []string{"payments", "payments-eu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewTableSelectModel("Namespace:", []Column{{Title: "Status"}}, slices.Clone(initial), "", 10, WithQuery(tt.filter))
+			m.cursor = tt.cursor
+
+			m.applyOptions(tt.msg)
+
+			equal := func(a, b Option) bool {
+				return a.Value == b.Value && slices.Equal(a.Cells, b.Cells)
+			}
+			if !slices.EqualFunc(m.options, tt.want, equal) {
+				t.Errorf("options = %v, want %v", m.options, tt.want)
+			}
+			if got := m.filteredOptions[m.cursor].Value; got != tt.wantCursor {
+				t.Errorf("cursor on %q, want %q", got, tt.wantCursor)
+			}
+			if tt.wantVisible != nil {
+				var visible []string
+				for _, opt := range m.filteredOptions {
+					visible = append(visible, opt.Value)
+				}
+				if !slices.Equal(visible, tt.wantVisible) {
+					t.Errorf("visible options = %v, want %v", visible, tt.wantVisible)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// Loader populates a selection prompt while it is already on screen. It should report options
+// through the sink as they become known, call Synced once the initial set is complete and keep
+// reporting changes until ctx is done.
+type Loader func(ctx context.Context, sink *Sink) error
+
+// Sink forwards option changes from a Loader to a running selection prompt.
+type Sink struct {
+	program *tea.Program
+}
+
+type (
+	optionsMsg struct {
+		added   []Option
+		removed []string
+	}
+	syncedMsg  struct{}
+	loadErrMsg struct{ err error }
+)
+
+// Add makes the given options available for selection. Options whose value is already present
+// replace the existing entry, which allows cells to be filled in as more details are known.
+func (s *Sink) Add(options ...Option) {
+	s.program.Send(optionsMsg{added: options})
+}
+
+// Remove withdraws the options with the given values from the prompt.
+func (s *Sink) Remove(values ...string) {
+	s.program.Send(optionsMsg{removed: values})
+}
+
+// Synced marks the initial set of options as complete, stopping the loading indicator.
+func (s *Sink) Synced() {
+	s.program.Send(syncedMsg{})
+}
+
+// SelectAsync runs an interactive selection prompt that is shown immediately and filled in by
+// load in the background. The prompt can be answered or cancelled before loading completes.
+// Options reported by load carry one cell per column.
+func SelectAsync(ctx context.Context, message string, columns []Column, load Loader, current string, pageSize int, opts ...SelectOption) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	model := NewTableSelectModel(message, columns, nil, current, pageSize, opts...)
+	model.loading = true
+	p := tea.NewProgram(model, programOptions(tea.WithContext(ctx))...)
+
+	go func() {
+		if err := load(ctx, &Sink{program: p}); err != nil && ctx.Err() == nil {
+			p.Send(loadErrMsg{err: err})
+		}
+	}()
+
+	finalModel, err := p.Run()
+	if err != nil {
+		if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+			return "", fmt.Errorf("selection aborted")
+		}
+		return "", fmt.Errorf("failed to run selection: %w", err)
+	}
+
+	return finishSelect(finalModel.(SelectModel))
+}
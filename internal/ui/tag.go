@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"io"
+	"os"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
+)
+
+// Tag is a colored marker shown next to an option, such as the environment of a context
+type Tag struct {
+	Text string
+	// Color is an ANSI color number or hex code; tags without a color use the default one
+	Color string
+}
+
+// String returns the tag without colors, or an empty string if the tag is empty
+func (t Tag) String() string {
+	if t.Text == "" {
+		return ""
+	}
+	return "[" + t.Text + "]"
+}
+
+// Render returns the tag in its color, or an empty string if the tag is empty
+func (t Tag) Render() string {
+	if t.Text == "" {
+		return ""
+	}
+	style := lipgloss.NewStyle().Bold(true)
+	if t.Color != "" {
+		style = style.Foreground(lipgloss.Color(t.Color))
+	}
+	return style.Render(t.String())
+}
+
+// WithTags shows the tags of each option after it and lets the filter match the tags' text
+func WithTags(tags func(value string) []Tag) SelectOption {
+	return func(m *SelectModel) {
+		m.tags = tags
+	}
+}
+
+// ColorWriter wraps w so that colors are only written as far as the terminal supports them,
+// and not at all when w is not a terminal or NO_COLOR is set
+func ColorWriter(w io.Writer) io.Writer {
+	return colorprofile.NewWriter(w, os.Environ())
+}
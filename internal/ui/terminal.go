@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"os"
+
+	tea "charm.land/bubbletea/v2"
+	"golang.org/x/term"
+)
+
+// programOptions returns the options shared by all prompts. When stdout is not a terminal, as in
+// eval "$(kubectl-switch ...)", prompts are drawn on stderr instead so they stay visible.
+func programOptions(opts ...tea.ProgramOption) []tea.ProgramOption {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		opts = append(opts, tea.WithOutput(os.Stderr))
+	}
+	return opts
+}
+
+// CanPrompt reports whether there is a terminal to show prompts on and read the answers from.
+func CanPrompt() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) &&
+		(term.IsTerminal(int(os.Stdout.Fd())) || term.IsTerminal(int(os.Stderr.Fd())))
+}
@@ -0,0 +1,134 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"charm.land/lipgloss/v2"
+)
+
+// SetTerminalTitle sets the title of the terminal window, which is the pane title inside tmux,
+// and colors the terminal tab in terminals that support it: iTerm2, kitty (with remote control
+// allowed) and tmux, where the window's entry in the status line is colored. An empty color
+// resets the tab color. The escape sequences are written to the controlling terminal, so that
+// they neither end up in redirected output nor depend on stdout being a terminal.
+func SetTerminalTitle(title, tabColor string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open terminal: %w", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	inTmux := os.Getenv("TMUX") != ""
+	var b strings.Builder
+	b.WriteString("\x1b]2;" + sanitizeTitle(title) + "\x07")
+	b.WriteString(passthrough(tabColorSequence(tabColor), inTmux))
+	if os.Getenv("KITTY_WINDOW_ID") != "" {
+		b.WriteString(passthrough(kittyTabColorSequence(tabColor), inTmux))
+	}
+
+	if _, err := tty.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	if inTmux {
+		return setTmuxWindowColor(os.Getenv("TMUX_PANE"), tabColor)
+	}
+	return nil
+}
+
+// tabColorSequence returns the iTerm2 escape sequence that sets the tab color, or resets it if
+// the color is empty.
+func tabColorSequence(tabColor string) string {
+	if tabColor == "" {
+		return "\x1b]6;1;bg;*;default\x07"
+	}
+	r, g, b := rgb(tabColor)
+	return fmt.Sprintf("\x1b]6;1;bg;red;brightness;%d\x07\x1b]6;1;bg;green;brightness;%d\x07\x1b]6;1;bg;blue;brightness;%d\x07", r, g, b)
+}
+
+// kittyTabColorSequence returns the kitty remote control command that sets the color of the tab
+// it is written in, or resets it if the color is empty. Kitty only runs it with the
+// allow_remote_control option enabled.
+func kittyTabColorSequence(tabColor string) string {
+	var color any // null resets the color
+	if tabColor != "" {
+		r, g, b := rgb(tabColor)
+		color = r<<16 | g<<8 | b
+	}
+	command, _ := json.Marshal(map[string]any{
+		"cmd":         "set-tab-color",
+		"version":     []int{0, 14, 2},
+		"no_response": true,
+		"payload": map[string]any{
+			"self":   true,
+			"colors": map[string]any{"active_bg": color, "inactive_bg": color},
+		},
+	})
+	return "\x1bP@kitty-cmd" + string(command) + "\x1b\\"
+}
+
+// tmuxStyleOptions are the tmux window options styling the window's entry in the status line.
+var tmuxStyleOptions = []string{"window-status-style", "window-status-current-style"}
+
+// setTmuxWindowColor colors the status line entry of the tmux window holding the given pane, on
+// top of the globally configured style, or restores the global style if the color is empty.
+func setTmuxWindowColor(pane, tabColor string) error {
+	for _, option := range tmuxStyleOptions {
+		args := []string{"set-option", "-w"}
+		if pane != "" {
+			args = append(args, "-t", pane)
+		}
+		if tabColor == "" {
+			args = append(args, "-u", option)
+		} else {
+			global, err := exec.Command("tmux", "show-options", "-gv", option).Output()
+			if err != nil {
+				return fmt.Errorf("failed to read tmux option %s: %w", option, err)
+			}
+			args = append(args, option, tmuxStyle(strings.TrimSpace(string(global)), tabColor))
+		}
+		if err := exec.Command("tmux", args...).Run(); err != nil {
+			return fmt.Errorf("failed to set tmux option %s: %w", option, err)
+		}
+	}
+	return nil
+}
+
+// tmuxStyle adds a background color to a tmux style, overriding any background it sets.
+func tmuxStyle(style, tabColor string) string {
+	r, g, b := rgb(tabColor)
+	bg := fmt.Sprintf("bg=#%02x%02x%02x", r, g, b)
+	if style == "" || style == "default" {
+		return bg
+	}
+	return style + "," + bg
+}
+
+// rgb returns the 8-bit red, green and blue components of a color.
+func rgb(color string) (uint32, uint32, uint32) {
+	r, g, b, _ := lipgloss.Color(color).RGBA()
+	return r >> 8, g >> 8, b >> 8
+}
+
+// passthrough wraps an escape sequence so that tmux forwards it to the outer terminal instead of
+// interpreting it. This requires the allow-passthrough tmux option.
+func passthrough(sequence string, inTmux bool) string {
+	if !inTmux {
+		return sequence
+	}
+	return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
+// sanitizeTitle drops control characters, which would end the escape sequence early.
+func sanitizeTitle(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, title)
+}
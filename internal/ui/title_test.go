@@ -0,0 +1,51 @@
+package ui
+
+import "testing"
+
+func TestTabColorSequences(t *testing.T) {
+	tests := []struct {
+		name      string
+		tabColor  string
+		style     string
+		wantITerm string
+		wantKitty string
+		wantTmux  string
+	}{
+		{
+			name:      "color",
+			tabColor:  "#ff8000",
+			wantITerm: "\x1b]6;1;bg;red;brightness;255\x07\x1b]6;1;bg;green;brightness;128\x07\x1b]6;1;bg;blue;brightness;0\x07",
+			wantKitty: `{"cmd":"set-tab-color","no_response":true,"payload":{"colors":{"active_bg":16744448,"inactive_bg":16744448},"self":true},"version":[0,14,2]}`,
+			wantTmux:  "bg=#ff8000",
+		},
+		{
+			name:      "color on top of a style",
+			tabColor:  "#ff8000",
+			style:     "fg=white,bg=black",
+			wantITerm: "\x1b]6;1;bg;red;brightness;255\x07\x1b]6;1;bg;green;brightness;128\x07\x1b]6;1;bg;blue;brightness;0\x07",
+			wantKitty: `{"cmd":"set-tab-color","no_response":true,"payload":{"colors":{"active_bg":16744448,"inactive_bg":16744448},"self":true},"version":[0,14,2]}`,
+			wantTmux:  "fg=white,bg=black,bg=#ff8000",
+		},
+		{
+			name:      "reset",
+			wantITerm: "\x1b]6;1;bg;*;default\x07",
+			wantKitty: `{"cmd":"set-tab-color","no_response":true,"payload":{"colors":{"active_bg":null,"inactive_bg":null},"self":true},"version":[0,14,2]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tabColorSequence(tt.tabColor); got != tt.wantITerm {
+				t.Errorf("tabColorSequence() = %q, want %q", got, tt.wantITerm)
+			}
+			if got, want := kittyTabColorSequence(tt.tabColor), "\x1bP@kitty-cmd"+tt.wantKitty+"\x1b\\"; got != want {
+				t.Errorf("kittyTabColorSequence() = %q, want %q", got, want)
+			}
+			if tt.tabColor == "" {
+				return
+			}
+			if got := tmuxStyle(tt.style, tt.tabColor); got != tt.wantTmux {
+				t.Errorf("tmuxStyle() = %q, want %q", got, tt.wantTmux)
+			}
+		})
+	}
+}
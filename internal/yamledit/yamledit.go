@@ -0,0 +1,116 @@
+// Package yamledit edits YAML files through their node tree, so that comments, the order of fields
+// and fields unknown to kubectl-switch survive the round trip.
+package yamledit
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// Parse parses a YAML document whose top level is a mapping, returning the document and that
+// mapping. Empty data yields a document with an empty mapping.
+func Parse(data []byte) (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("expected a mapping at the top level")
+	}
+	return &doc, doc.Content[0], nil
+}
+
+// Encode encodes a document parsed from original. Sequences are indented the way they were in
+// original, so that files written by kubectl (which does not indent them) keep their layout.
+func Encode(doc *yaml.Node, original []byte) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if compactSequences(original) {
+		encoder.CompactSeqIndent()
+	}
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// compactSequences reports whether the first block sequence in data starts at the same column as
+// the key it belongs to.
+func compactSequences(data []byte) bool {
+	previous := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if (trimmed == "-" || strings.HasPrefix(trimmed, "- ")) && strings.HasSuffix(strings.TrimRight(previous, " "), ":") {
+			return len(line)-len(trimmed) == len(previous)-len(strings.TrimLeft(previous, " "))
+		}
+		previous = line
+	}
+	return false
+}
+
+// Lookup returns the value of key in a mapping node, or nil if it is missing.
+func Lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Value returns the value of key in a mapping node, adding it with the given kind if the key is
+// missing or holds a different kind of value.
+func Value(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	if value := Lookup(mapping, key); value != nil {
+		if value.Kind != kind {
+			*value = yaml.Node{Kind: kind}
+		}
+		return value
+	}
+
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, String(key), value)
+	return value
+}
+
+// SetString sets key to a string in a mapping node, removing the key if value is empty.
+func SetString(mapping *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		if value == "" {
+			mapping.Content = slices.Delete(mapping.Content, i, i+2)
+		} else {
+			mapping.Content[i+1] = String(value)
+		}
+		return
+	}
+	if value != "" {
+		mapping.Content = append(mapping.Content, String(key), String(value))
+	}
+}
+
+// String returns a scalar node that reads back as the string value. Values such as 123, true or no
+// are quoted, as YAML parsers (including the YAML 1.1 one kubectl uses) would otherwise read them
+// as numbers or booleans.
+func String(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	var parsed any
+	if err := k8syaml.Unmarshal([]byte(value), &parsed); err != nil || parsed != value {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+	return node
+}